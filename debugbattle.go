@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+
+	"corewar/mars"
 )
 
 // DebugBattle runs a battle with detailed debugging
@@ -9,12 +11,14 @@ func DebugBattle() {
 	fmt.Println("=== DEBUG: Imp vs Dwarf ===")
 
 	// Create small core for debugging
-	core := NewCore(100)
-	vm := NewVM(core)
+	config := mars.DefaultConfig()
+	config.CoreSize = 100
+	core := mars.NewCore(config.CoreSize)
+	vm := mars.NewVM(core, config)
 
 	// Create warriors
-	imp := CreateImp()
-	dwarf := CreateDwarf()
+	imp := mars.CreateImp()
+	dwarf := mars.CreateDwarf()
 
 	// Load at known positions
 	impStart := 0
@@ -22,23 +26,21 @@ func DebugBattle() {
 
 	// Load Imp
 	for i, inst := range imp.Code {
-		core.cells[impStart+i] = inst
-		core.owners[impStart+i] = imp.Color
+		core.Load(impStart+i, inst, imp)
 	}
-	vm.AddProcess(imp, impStart)
+	vm.AddProcess(imp, impStart+imp.StartOffset)
 
 	// Load Dwarf
 	for i, inst := range dwarf.Code {
-		core.cells[dwarfStart+i] = inst
-		core.owners[dwarfStart+i] = dwarf.Color
+		core.Load(dwarfStart+i, inst, dwarf)
 	}
-	vm.AddProcess(dwarf, dwarfStart)
+	vm.AddProcess(dwarf, dwarfStart+dwarf.StartOffset)
 
 	// Show initial state
 	fmt.Printf("\nInitial Dwarf code at %d:\n", dwarfStart)
 	for i := 0; i < 4; i++ {
-		inst := core.cells[dwarfStart+i]
-		fmt.Printf("  %d: %s A=%d B=%d\n", dwarfStart+i, OpCodeString(inst.Op), inst.A, inst.B)
+		inst := core.Cell(dwarfStart + i)
+		fmt.Printf("  %d: %s A=%d B=%d\n", dwarfStart+i, mars.OpCodeString(inst.Op), inst.A, inst.B)
 	}
 
 	// Run first 10 cycles
@@ -47,7 +49,7 @@ func DebugBattle() {
 
 		// Show DAT pointer value
 		datAddr := dwarfStart + 3
-		dat := core.cells[datAddr]
+		dat := core.Cell(datAddr)
 		fmt.Printf("Dwarf DAT at %d: B=%d\n", datAddr, dat.B)
 
 		// Execute one cycle
@@ -55,13 +57,13 @@ func DebugBattle() {
 
 		// Check for bombs
 		bombAddr := (datAddr + dat.B) % 100
-		if core.cells[bombAddr].Op == DAT && core.owners[bombAddr] == dwarf.Color {
+		if core.Cell(bombAddr).Op == mars.DAT && core.Owner(bombAddr) == dwarf.Color {
 			fmt.Printf("BOMB placed at %d!\n", bombAddr)
 		}
 
 		// Show Imp position
-		if len(vm.processes) > 0 && vm.processes[0].alive {
-			fmt.Printf("Imp at position %d\n", vm.processes[0].pc)
+		if pcs := vm.ProcessPCs(imp); len(pcs) > 0 {
+			fmt.Printf("Imp at position %d\n", pcs[0])
 		}
 	}
 }
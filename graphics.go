@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"image/color"
 
+	"corewar/mars"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -16,6 +18,8 @@ func (g *Game) DrawCore(screen *ebiten.Image) {
 	availableWidth := screenWidth - 2*margin
 	availableHeight := screenHeight - infoHeight - 2*margin
 
+	coreSize := g.config.CoreSize
+
 	// Calculate optimal cell size
 	// Try different column counts to find the best fit
 	bestCellSize := 1
@@ -77,10 +81,11 @@ func (g *Game) DrawCore(screen *ebiten.Image) {
 		}
 
 		// Get base color
-		baseColor := GetWarriorColor(g.core.owners[i])
+		owner := g.core.Owner(i)
+		baseColor := warriorColor(owner)
 
 		// Check if it's a DAT instruction (bomb)
-		isDat := g.core.cells[i].Op == DAT && g.core.owners[i] != Empty
+		isDat := g.core.Cell(i).Op == mars.DAT && owner != mars.Empty
 
 		// Apply effects
 		r, g_, b := float32(baseColor.R), float32(baseColor.G), float32(baseColor.B)
@@ -92,23 +97,25 @@ func (g *Game) DrawCore(screen *ebiten.Image) {
 			b = b * 0.5
 		}
 
+		readEffect, writeEffect, execEffect := g.core.Effects(i)
+
 		// Execution effect (brighten)
-		if g.core.execEffect[i] > 0 {
-			factor := 1 + g.core.execEffect[i]
+		if execEffect > 0 {
+			factor := 1 + execEffect
 			r = min(255, r*factor)
 			g_ = min(255, g_*factor)
 			b = min(255, b*factor)
 		}
 
 		// Write effect (yellow tint)
-		if g.core.writeEffect[i] > 0 {
-			r = min(255, r+200*g.core.writeEffect[i])
-			g_ = min(255, g_+200*g.core.writeEffect[i])
+		if writeEffect > 0 {
+			r = min(255, r+200*writeEffect)
+			g_ = min(255, g_+200*writeEffect)
 		}
 
 		// Read effect (green tint)
-		if g.core.readEffect[i] > 0 {
-			g_ = min(255, g_+200*g.core.readEffect[i])
+		if readEffect > 0 {
+			g_ = min(255, g_+200*readEffect)
 		}
 
 		cellColor := color.RGBA{uint8(r), uint8(g_), uint8(b), 255}
@@ -123,9 +130,9 @@ func (g *Game) DrawCore(screen *ebiten.Image) {
 		coreSize, actualCols, gridRows), 10, int(infoY))
 
 	// Draw cell ownership stats
-	cellCounts := make(map[WarriorColor]int)
+	cellCounts := make(map[mars.WarriorColor]int)
 	for i := 0; i < coreSize; i++ {
-		cellCounts[g.core.owners[i]]++
+		cellCounts[g.core.Owner(i)]++
 	}
 
 	x := 300
@@ -152,18 +159,13 @@ func (g *Game) DrawUI(screen *ebiten.Image) {
 	// Draw warrior info
 	y := int(infoY) + 30
 	for _, w := range g.warriors {
-		colorBox := GetWarriorColor(w.Color)
+		colorBox := warriorColor(w.Color)
 
 		// Draw color indicator
 		vector.DrawFilledRect(screen, 10, float32(y), 20, 20, colorBox, false)
 
 		// Count processes
-		processCount := 0
-		for _, p := range g.vm.processes {
-			if p.warrior == w && p.alive {
-				processCount++
-			}
-		}
+		processCount := g.vm.ProcessCount(w)
 
 		// Draw warrior info
 		status := "ALIVE"
@@ -173,8 +175,8 @@ func (g *Game) DrawUI(screen *ebiten.Image) {
 
 		// Count DAT bombs placed by this warrior
 		datCount := 0
-		for j := 0; j < g.core.size; j++ {
-			if g.core.owners[j] == w.Color && g.core.cells[j].Op == DAT {
+		for j := 0; j < g.core.Size(); j++ {
+			if g.core.Owner(j) == w.Color && g.core.Cell(j).Op == mars.DAT {
 				datCount++
 			}
 		}
@@ -195,9 +197,9 @@ func (g *Game) DrawUI(screen *ebiten.Image) {
 	}
 	if g.gameOver {
 		pauseText = "GAME OVER"
-		if g.battleMgr.stats.Winner != nil {
-			pauseText = fmt.Sprintf("WINNER: %s", g.battleMgr.stats.Winner.Name)
-		} else if g.battleMgr.stats.IsDraw {
+		if stats := g.battleMgr.Stats(); stats.Winner != nil {
+			pauseText = fmt.Sprintf("WINNER: %s", stats.Winner.Name)
+		} else if stats.IsDraw {
 			pauseText = "DRAW"
 		}
 	}
@@ -206,7 +208,7 @@ func (g *Game) DrawUI(screen *ebiten.Image) {
 	y += 20
 	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Speed: %d cycles/frame", g.speed), controlsX, y)
 	y += 20
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Cycle: %d / %d", g.cycle, maxCycles), controlsX, y)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Cycle: %d / %d", g.cycle, g.config.MaxCycles), controlsX, y)
 
 	// Draw control instructions
 	instructionsX := screenWidth - 300
@@ -223,6 +225,22 @@ func (g *Game) DrawUI(screen *ebiten.Image) {
 	ebitenutil.DebugPrintAt(screen, "ESC - Exit", instructionsX, y)
 }
 
+// warriorColor returns the display color for a given warrior
+func warriorColor(wc mars.WarriorColor) color.RGBA {
+	switch wc {
+	case mars.Red:
+		return color.RGBA{255, 0, 0, 255}
+	case mars.Blue:
+		return color.RGBA{0, 0, 255, 255}
+	case mars.Green:
+		return color.RGBA{0, 255, 0, 255}
+	case mars.Yellow:
+		return color.RGBA{255, 255, 0, 255}
+	default:
+		return color.RGBA{50, 50, 50, 255}
+	}
+}
+
 // min returns the minimum of two float32 values
 func min(a, b float32) float32 {
 	if a < b {
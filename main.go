@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+
+	"corewar/mars"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -12,42 +15,43 @@ import (
 const (
 	screenWidth  = 1024
 	screenHeight = 768
-	coreSize     = 8000  // Standard core size
-	maxCycles    = 80000 // Maximum cycles before draw
-	debugMode    = false // Set to true for debug output
-	cellSpacing  = 1     // Spacing between cells
-	infoHeight   = 100   // Height of info panel
+	cellSpacing  = 1   // Spacing between cells
+	infoHeight   = 100 // Height of info panel
 )
 
 // Game represents the main game state
 type Game struct {
-	core         *Core
-	vm           *VM
-	warriors     []*Warrior
+	core         *mars.Core
+	vm           *mars.VM
+	warriors     []*mars.Warrior
 	paused       bool
 	speed        int // cycles per frame
 	cycle        int
 	keyPressed   map[ebiten.Key]bool
-	battleMgr    *BattleManager
+	battleMgr    *mars.BattleManager
+	config       mars.Config
 	gameOver     bool
 	battleReport string
 }
 
 // NewGame creates a new game instance
-func NewGame(warriors []*Warrior) *Game {
+func NewGame(warriors []*mars.Warrior, config mars.Config) (*Game, error) {
 	g := &Game{
 		speed:      10,
 		keyPressed: make(map[ebiten.Key]bool),
-		battleMgr:  NewBattleManager(coreSize, maxCycles),
+		battleMgr:  mars.NewBattleManager(config),
+		config:     config,
 	}
 
 	// Setup battle
-	g.battleMgr.SetupBattle(warriors)
-	g.core = g.battleMgr.core
-	g.vm = g.battleMgr.vm
+	if err := g.battleMgr.SetupBattle(warriors); err != nil {
+		return nil, err
+	}
+	g.core = g.battleMgr.Core()
+	g.vm = g.battleMgr.VM()
 	g.warriors = warriors
 
-	return g
+	return g, nil
 }
 
 // Update handles game logic updates
@@ -76,7 +80,11 @@ func (g *Game) Update() error {
 
 	if ebiten.IsKeyPressed(ebiten.KeyR) {
 		// Restart the game
-		*g = *NewGame(g.warriors)
+		game, err := NewGame(g.warriors, g.config)
+		if err != nil {
+			return err
+		}
+		*g = *game
 	}
 
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
@@ -93,7 +101,7 @@ func (g *Game) Update() error {
 				log.Print(g.battleReport)
 				break
 			}
-			g.cycle = g.battleMgr.stats.TotalCycles
+			g.cycle = g.battleMgr.Stats().TotalCycles
 		}
 	}
 
@@ -114,41 +122,145 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return screenWidth, screenHeight
 }
 
+// loadWarrior loads a warrior from Redcode source, printing any
+// assembler warnings, or from a load file
+func loadWarrior(filename string, color mars.WarriorColor, config mars.Config, loadFile bool) (*mars.Warrior, error) {
+	if loadFile {
+		return mars.LoadWarriorFromLoadFile(filename, color)
+	}
+	warrior, err := mars.LoadWarriorFromFile(filename, color, config)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warrior.Warnings {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	return warrior, nil
+}
+
+// loadAllWarriors loads every warrior of a Redcode source file, printing
+// any assembler warnings, or the warrior of a load file
+func loadAllWarriors(filename string, config mars.Config, loadFile bool) ([]*mars.Warrior, error) {
+	if loadFile {
+		warrior, err := mars.LoadWarriorFromLoadFile(filename, mars.Red)
+		if err != nil {
+			return nil, err
+		}
+		return []*mars.Warrior{warrior}, nil
+	}
+	warriors, err := mars.LoadWarriorsFromFile(filename, config)
+	if err != nil {
+		return nil, err
+	}
+	for _, warrior := range warriors {
+		for _, warning := range warrior.Warnings {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+	}
+	return warriors, nil
+}
+
 func main() {
 	// Parse command line flags
-	mode := flag.String("mode", "visual", "Game mode: visual, battle, or tournament")
+	mode := flag.String("mode", "visual", "Game mode: visual, battle, tournament, assemble (print the warriors of -w1 and -w2 as load files), or disassemble (list them as Redcode)")
 	warrior1 := flag.String("w1", "", "Path to first warrior file")
 	warrior2 := flag.String("w2", "", "Path to second warrior file")
+	loadFiles := flag.Bool("loadfile", false, "Read -w1 and -w2 as load files instead of Redcode source")
+	bundlePath := flag.String("bundle", "", "Tournament mode: load the warriors of a bundle (directory or tar archive with a bundle.json) instead of warriors/")
 	rounds := flag.Int("rounds", 10, "Number of rounds for tournament mode")
+	melee := flag.Bool("melee", false, "Tournament mode: fight all warriors in one core each round instead of in pairs")
+	workers := flag.Int("workers", 0, "Tournament mode: matches run at the same time (0 uses one per CPU)")
+	absolute := flag.Bool("absolute", false, "Disassemble mode: show addresses instead of relative offsets")
+	labels := flag.Bool("labels", false, "Disassemble mode: name jump targets with labels")
+	verbose := flag.Bool("verbose", false, "Tournament mode: also list every round with its seed and cycle count")
+	preset := flag.String("preset", "94nop", "Hill settings preset: "+strings.Join(mars.PresetNames(), ", "))
+	coreSizeFlag := flag.Int("coresize", 0, "Core size (overrides preset)")
+	maxCyclesFlag := flag.Int("cycles", 0, "Cycles before a draw is declared (overrides preset)")
+	maxProcesses := flag.Int("processes", 0, "Maximum processes per warrior (overrides preset)")
+	maxLength := flag.Int("maxlength", 0, "Maximum warrior length (overrides preset)")
+	minDistance := flag.Int("mindistance", 0, "Minimum distance between warriors (overrides preset)")
+	readLimit := flag.Int("readlimit", 0, "Read distance limit (overrides preset)")
+	writeLimit := flag.Int("writelimit", 0, "Write distance limit (overrides preset)")
+	pspaceSize := flag.Int("pspacesize", 0, "P-space size (overrides preset)")
+	seed := flag.Int64("seed", 0, "Seed for warrior placement, to replay a battle or tournament (0 picks one from the clock)")
+	debug := flag.Bool("debug", false, "Print process deaths while battling")
+	rulesName := flag.String("rules", "standard", "Rule set: standard, or ownership (processes die executing cells overwritten by another warrior)")
 	flag.Parse()
 
+	// Build the simulation settings from the preset and any overrides
+	config, err := mars.PresetConfig(*preset)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *coreSizeFlag > 0 {
+		config.CoreSize = *coreSizeFlag
+		// Limits follow the core size unless set explicitly
+		config.ReadLimit = *coreSizeFlag
+		config.WriteLimit = *coreSizeFlag
+		config.PSpaceSize = max(1, *coreSizeFlag/16)
+	}
+	if *maxCyclesFlag > 0 {
+		config.MaxCycles = *maxCyclesFlag
+	}
+	if *maxProcesses > 0 {
+		config.MaxProcesses = *maxProcesses
+	}
+	if *maxLength > 0 {
+		config.MaxLength = *maxLength
+	}
+	if *minDistance > 0 {
+		config.MinDistance = *minDistance
+	}
+	if *readLimit > 0 {
+		config.ReadLimit = *readLimit
+	}
+	if *writeLimit > 0 {
+		config.WriteLimit = *writeLimit
+	}
+	if *pspaceSize > 0 {
+		config.PSpaceSize = *pspaceSize
+	}
+	config.Rules, err = mars.ParseRuleSet(*rulesName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Invalid settings: %v", err)
+	}
+
 	// Load warriors based on mode
-	var warriors []*Warrior
+	var warriors []*mars.Warrior
 
 	switch *mode {
 	case "visual":
 		// Visual mode - interactive graphics
 		if *warrior1 != "" && *warrior2 != "" {
 			// Load specified warriors
-			w1, err := LoadWarriorFromFile(*warrior1, Red)
+			w1, err := loadWarrior(*warrior1, mars.Red, config, *loadFiles)
 			if err != nil {
 				log.Fatalf("Error loading warrior 1: %v", err)
 			}
-			w2, err := LoadWarriorFromFile(*warrior2, Blue)
+			w2, err := loadWarrior(*warrior2, mars.Blue, config, *loadFiles)
 			if err != nil {
 				log.Fatalf("Error loading warrior 2: %v", err)
 			}
-			warriors = []*Warrior{w1, w2}
+			warriors = []*mars.Warrior{w1, w2}
 		} else {
 			// Use default warriors
-			warriors = []*Warrior{CreateImp(), CreateDwarf()}
+			warriors = []*mars.Warrior{mars.CreateImp(), mars.CreateDwarf()}
 		}
 
 		// Run visual game
 		ebiten.SetWindowSize(screenWidth, screenHeight)
 		ebiten.SetWindowTitle("Core War")
 
-		game := NewGame(warriors)
+		game, err := NewGame(warriors, config)
+		if err != nil {
+			log.Fatalf("Error setting up battle: %v", err)
+		}
+		if *debug {
+			game.vm.SetTrace(os.Stdout)
+		}
 
 		if err := ebiten.RunGame(game); err != nil {
 			log.Fatal(err)
@@ -161,18 +273,26 @@ func main() {
 			os.Exit(1)
 		}
 
-		w1, err := LoadWarriorFromFile(*warrior1, Red)
+		w1, err := loadWarrior(*warrior1, mars.Red, config, *loadFiles)
 		if err != nil {
 			log.Fatalf("Error loading warrior 1: %v", err)
 		}
-		w2, err := LoadWarriorFromFile(*warrior2, Blue)
+		w2, err := loadWarrior(*warrior2, mars.Blue, config, *loadFiles)
 		if err != nil {
 			log.Fatalf("Error loading warrior 2: %v", err)
 		}
 
 		// Run battle
-		bm := NewBattleManager(coreSize, maxCycles)
-		bm.SetupBattle([]*Warrior{w1, w2})
+		bm := mars.NewBattleManager(config)
+		if *seed != 0 {
+			bm.SetSeed(*seed)
+		}
+		if err := bm.SetupBattle([]*mars.Warrior{w1, w2}); err != nil {
+			log.Fatalf("Error setting up battle: %v", err)
+		}
+		if *debug {
+			bm.VM().SetTrace(os.Stdout)
+		}
 
 		fmt.Printf("Battle: %s vs %s\n", w1.Name, w2.Name)
 		fmt.Println("Running battle...")
@@ -190,29 +310,145 @@ func main() {
 
 	case "tournament":
 		// Tournament mode - round-robin tournament
-		// Load all warriors from warriors directory or specified files
-		allWarriors, err := LoadAllWarriors()
+		// Load all warriors from the bundle or the warriors directory
+		config.Rounds = *rounds
+		var bundle *mars.Bundle
+		loadWarriors := func() ([]*mars.Warrior, []error, error) {
+			if *bundlePath == "" {
+				return mars.LoadAllWarriors("warriors", config)
+			}
+			var err error
+			if bundle, err = mars.LoadBundle(*bundlePath, config); err != nil {
+				return nil, nil, err
+			}
+			return bundle.Warriors, nil, nil
+		}
+		allWarriors, failures, err := loadWarriors()
+		if *melee && err == nil && len(allWarriors) != config.Warriors {
+			// Melee warriors are assembled knowing how many share the core
+			config.Warriors = len(allWarriors)
+			allWarriors, failures, err = loadWarriors()
+		}
 		if err != nil {
 			log.Fatalf("Error loading warriors: %v", err)
 		}
+		if len(failures) > 0 {
+			fmt.Fprintln(os.Stderr, "Skipping warriors that failed to load:")
+			for _, failure := range failures {
+				fmt.Fprintln(os.Stderr, failure)
+			}
+		}
 
-		if len(allWarriors) < 2 {
+		if bundle != nil {
+			if len(allWarriors) < 2 {
+				log.Fatalf("Bundle %s has fewer than two warriors", bundle.Name)
+			}
+			if bundle.Preset != "" && bundle.Preset != *preset {
+				fmt.Fprintf(os.Stderr, "Warning: bundle %s was written for the %s preset\n", bundle.Name, bundle.Preset)
+			}
+		} else if len(allWarriors) < 2 {
 			// Use built-in warriors
-			allWarriors = []*Warrior{
-				CreateImp(),
-				CreateDwarf(),
-				CreateBomber(),
-				CreateQuickScan(),
+			fmt.Fprintln(os.Stderr, "Fewer than two warriors loaded from warriors/, using the built-in warriors")
+			allWarriors = []*mars.Warrior{
+				mars.CreateImp(),
+				mars.CreateDwarf(),
+				mars.CreateBomber(),
+				mars.CreateQuickScan(),
 			}
 		}
 
 		// Run tournament
-		tournament := NewTournament(allWarriors, *rounds, coreSize, maxCycles)
-		tournament.Run()
+		tournament, err := mars.NewTournament(allWarriors, *rounds, config)
+		if err != nil {
+			log.Fatalf("Error setting up tournament: %v", err)
+		}
+		tournament.SetMelee(*melee)
+		tournament.SetWorkers(*workers)
+		if *seed != 0 {
+			tournament.SetSeed(*seed)
+		}
+
+		fmt.Println("Starting Tournament...")
+		if bundle != nil {
+			fmt.Printf("Bundle: %s\n", bundle.Name)
+		}
+		if *melee {
+			fmt.Printf("Melee: %d warriors, Rounds: %d, Seed: %d\n", len(allWarriors), *rounds, tournament.Seed())
+		} else {
+			fmt.Printf("Warriors: %d, Rounds per match: %d, Seed: %d\n", len(allWarriors), *rounds, tournament.Seed())
+		}
+		if err := tournament.Run(); err != nil {
+			log.Fatalf("Error running tournament: %v", err)
+		}
+		fmt.Println()
+		tournament.WriteResults(os.Stdout)
+		if *verbose {
+			fmt.Println()
+			tournament.WriteRounds(os.Stdout)
+		}
+
+	case "assemble":
+		// Assemble mode - print warriors as load files
+		if *warrior1 == "" {
+			fmt.Println("Assemble mode requires a warrior: -w1 <file> [-w2 <file>]")
+			os.Exit(1)
+		}
+
+		// Every warrior of a file with several is printed
+		printed := 0
+		for i, filename := range []string{*warrior1, *warrior2} {
+			if filename == "" {
+				continue
+			}
+			fileWarriors, err := loadAllWarriors(filename, config, *loadFiles)
+			if err != nil {
+				log.Fatalf("Error loading warrior %d: %v", i+1, err)
+			}
+			for _, warrior := range fileWarriors {
+				if printed > 0 {
+					fmt.Println()
+				}
+				if err := mars.WriteLoadFile(os.Stdout, warrior); err != nil {
+					log.Fatal(err)
+				}
+				printed++
+			}
+		}
+
+	case "disassemble":
+		// Disassemble mode - list warriors as Redcode
+		if *warrior1 == "" {
+			fmt.Println("Disassemble mode requires a warrior: -w1 <file> [-w2 <file>]")
+			os.Exit(1)
+		}
+
+		options := mars.DisassembleOptions{
+			CoreSize: config.CoreSize,
+			Absolute: *absolute,
+			Labels:   *labels,
+		}
+		// Every warrior of a file with several is listed
+		printed := 0
+		for i, filename := range []string{*warrior1, *warrior2} {
+			if filename == "" {
+				continue
+			}
+			fileWarriors, err := loadAllWarriors(filename, config, *loadFiles)
+			if err != nil {
+				log.Fatalf("Error loading warrior %d: %v", i+1, err)
+			}
+			for _, warrior := range fileWarriors {
+				if printed > 0 {
+					fmt.Println()
+				}
+				fmt.Print(mars.DisassembleWarrior(warrior, options))
+				printed++
+			}
+		}
 
 	default:
 		fmt.Printf("Unknown mode: %s\n", *mode)
-		fmt.Println("Available modes: visual, battle, tournament")
+		fmt.Println("Available modes: visual, battle, tournament, assemble, disassemble")
 		os.Exit(1)
 	}
 }
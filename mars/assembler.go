@@ -0,0 +1,842 @@
+package mars
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version is the simulator version seen by warriors as VERSION, written
+// as in pMARS: major*100 + minor*10 + patch
+const Version = 100
+
+// Assembler converts Redcode source to instructions
+type Assembler struct {
+	config Config // Settings seen through the predefined constants
+	file   string // Source file name shown in diagnostics
+
+	// State of the source being parsed
+	source      []string          // Lines of the source
+	labels      map[string]int    // Instruction index of each label
+	labelLines  map[string]int    // Source line defining each label
+	used        map[string]bool   // Labels referred to by an expression
+	equs        map[string]string // EQU text, by name
+	checks      []sourceLine      // Expressions of ;assert comments
+	origin      int               // Entry point set by ORG or END
+	originLine  int               // Source line of the ORG or END
+	pin         int
+	hasPIN      bool
+	diagnostics Diagnostics
+}
+
+// sourceLine is a preprocessed line along with the line of the original
+// source it comes from
+type sourceLine struct {
+	text string // Without comments or surrounding blanks
+	line int    // 1-based line in the original source
+}
+
+// NewAssembler creates a new assembler for warriors that will run under
+// config
+func NewAssembler(config Config) *Assembler {
+	return &Assembler{
+		config: config,
+	}
+}
+
+// SetFile sets the source file name shown in diagnostics
+func (a *Assembler) SetFile(name string) {
+	a.file = name
+}
+
+// maxEQUDepth bounds nested EQU substitution, so that definitions that
+// refer to themselves are reported instead of expanding forever
+const maxEQUDepth = 64
+
+// Parse converts Redcode source into instructions. It carries on past
+// errors to report as many problems as it can: if any of them is an
+// error, the Diagnostics are returned as the error. Warnings of a
+// successful parse are available from Diagnostics.
+func (a *Assembler) Parse(source string) ([]Instruction, error) {
+	a.reset(source)
+
+	// Preprocess: substitute EQUs, then unroll FOR loops
+	lines := a.expandLoops(a.expandEQUs(), 0)
+	instructions := make([]Instruction, 0)
+
+	// First pass: collect labels
+	lineNum := 0
+	for _, line := range lines {
+		labels, text := splitLabels(line.text)
+
+		// Stop at END and skip ORG and PIN directives
+		directive := strings.ToUpper(firstField(text))
+		if directive == "END" {
+			break
+		}
+		for _, label := range labels {
+			a.defineLabel(label, lineNum, line.line)
+		}
+		if text == "" || directive == "ORG" || directive == "PIN" {
+			continue
+		}
+
+		lineNum++
+	}
+
+	// Second pass: parse instructions
+	lineNum = 0
+	for _, line := range lines {
+		_, text := splitLabels(line.text)
+		if text == "" {
+			continue
+		}
+		keyword := firstField(text)
+		directive := strings.ToUpper(keyword)
+		argument := strings.TrimSpace(text[len(keyword):])
+
+		switch directive {
+		case "ORG", "END":
+			// ORG, or END with an operand, sets the entry point
+			if argument != "" {
+				if origin, ok := a.evaluateAt(line.line, argument, 0); ok {
+					a.origin, a.originLine = origin, line.line
+				}
+			} else if directive == "ORG" {
+				a.errorf(line.line, keyword, "ORG requires a start address")
+			}
+
+		case "PIN":
+			if argument == "" {
+				a.errorf(line.line, keyword, "PIN requires a value")
+			} else if pin, ok := a.evaluateAt(line.line, argument, lineNum); ok {
+				a.pin, a.hasPIN = pin, true
+			}
+
+		default:
+			if inst, ok := a.parseInstruction(line, text, lineNum); ok {
+				instructions = append(instructions, inst)
+			}
+			lineNum++
+		}
+
+		// Nothing after END is assembled
+		if directive == "END" {
+			break
+		}
+	}
+
+	if lineNum == 0 {
+		// Empty or comment-only source, which would load as a warrior
+		// that dies on its first cycle
+		a.errorf(1, "", "no instructions")
+	} else if a.origin < 0 || (a.origin > 0 && a.origin >= lineNum) {
+		a.errorf(a.originLine, "", "start address %d is outside the warrior", a.origin)
+	}
+	a.checkAsserts()
+	a.checkLabels()
+
+	sort.SliceStable(a.diagnostics, func(i, j int) bool {
+		return a.diagnostics[i].Line < a.diagnostics[j].Line
+	})
+	if a.diagnostics.HasErrors() {
+		return nil, a.diagnostics
+	}
+	return instructions, nil
+}
+
+// reset prepares the assembler to parse a new source
+func (a *Assembler) reset(source string) {
+	a.source = strings.Split(source, "\n")
+	for i, line := range a.source {
+		a.source[i] = strings.TrimSuffix(line, "\r")
+	}
+	a.labels = make(map[string]int)
+	a.labelLines = make(map[string]int)
+	a.used = make(map[string]bool)
+	a.equs = make(map[string]string)
+	a.checks = nil
+	a.origin, a.originLine = 0, 0
+	a.pin, a.hasPIN = 0, false
+	a.diagnostics = nil
+}
+
+// Diagnostics returns the errors and warnings found in the last parsed
+// source, in line order
+func (a *Assembler) Diagnostics() Diagnostics {
+	return a.diagnostics
+}
+
+// Origin returns the entry point declared by the last parsed source with
+// ORG or END, as an index into its instructions. It is 0 when the source
+// declares none.
+func (a *Assembler) Origin() int {
+	return a.origin
+}
+
+// PIN returns the P-space identification number declared by the last
+// parsed source, and whether one was declared
+func (a *Assembler) PIN() (int, bool) {
+	return a.pin, a.hasPIN
+}
+
+// errorf records an error on a source line, pointing at the first
+// occurrence of at in the line
+func (a *Assembler) errorf(line int, at, format string, args ...any) {
+	a.report(SeverityError, line, at, fmt.Sprintf(format, args...))
+}
+
+// warnf records a warning on a source line, pointing at the first
+// occurrence of at in the line
+func (a *Assembler) warnf(line int, at, format string, args ...any) {
+	a.report(SeverityWarning, line, at, fmt.Sprintf(format, args...))
+}
+
+// report adds a diagnostic. Lines repeated by FOR loops report the same
+// problem only once.
+func (a *Assembler) report(severity Severity, line int, at, message string) {
+	d := Diagnostic{
+		Severity: severity,
+		File:     a.file,
+		Line:     line,
+		Message:  message,
+	}
+	if line >= 1 && line <= len(a.source) {
+		d.Source = a.source[line-1]
+		d.Column = column(d.Source, at)
+	}
+
+	for _, existing := range a.diagnostics {
+		if existing == d {
+			return
+		}
+	}
+	a.diagnostics = append(a.diagnostics, d)
+}
+
+// column returns the 1-based column of at in a source line, or of the
+// first non-blank character when at is empty or not found. Only the code
+// before a comment is searched, except on lines that are all comment,
+// such as ;assert. A name only matches whole, not as part of a longer
+// name.
+func column(source, at string) int {
+	code := source
+	if i := strings.Index(source, ";"); i >= 0 && strings.TrimSpace(source[:i]) != "" {
+		code = source[:i]
+	}
+
+	for start := 0; at != ""; {
+		i := strings.Index(code[start:], at)
+		if i < 0 {
+			break
+		}
+		i += start
+		end := i + len(at)
+		if !isIdentStart(at[0]) ||
+			((i == 0 || !isIdentChar(code[i-1])) && (end == len(code) || !isIdentChar(code[end]))) {
+			return i + 1
+		}
+		start = i + 1
+	}
+	return len(source) - len(strings.TrimLeft(source, " \t")) + 1
+}
+
+// defineLabel records the instruction index of a label. A label that is
+// already defined keeps its first definition.
+func (a *Assembler) defineLabel(label string, index, line int) {
+	if first, ok := a.labelLines[label]; ok {
+		a.warnf(line, label, "label %s already defined on line %d", label, first)
+		return
+	}
+	a.labels[label] = index
+	a.labelLines[label] = line
+}
+
+// checkAsserts reports every ;assert that does not hold under the
+// configured settings
+func (a *Assembler) checkAsserts() {
+	for _, check := range a.checks {
+		expr, err := a.substitute(check.text, 0)
+		if err != nil {
+			a.errorf(check.line, check.text, "%v", err)
+			continue
+		}
+		if value, ok := a.evaluateAt(check.line, expr, 0); ok && value == 0 {
+			a.errorf(check.line, check.text, "assertion failed: %s", check.text)
+		}
+	}
+}
+
+// checkLabels warns about labels that no expression refers to
+func (a *Assembler) checkLabels() {
+	names := make([]string, 0, len(a.labelLines))
+	for name := range a.labelLines {
+		if !a.used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.warnf(a.labelLines[name], name, "label %s is never used", name)
+	}
+}
+
+// expandEQUs removes comments, blank lines and EQU definitions from the
+// source and substitutes the text of every EQU where its name is used.
+// The expressions of ;assert comments are kept for Parse to check.
+//
+// A definition is written "name EQU text". Lines of the form "EQU text"
+// right after a definition continue it: the name then stands for several
+// lines, and a line holding the name is replaced by all of them.
+func (a *Assembler) expandEQUs() []sourceLine {
+	var body []sourceLine
+	current := "" // Name of the definition that EQU lines continue
+
+	for i, raw := range a.source {
+		lineNum := i + 1
+		if check, ok := strings.CutPrefix(strings.TrimSpace(raw), ";assert"); ok {
+			a.checks = append(a.checks, sourceLine{text: strings.TrimSpace(check), line: lineNum})
+		}
+
+		text := stripComment(raw)
+		if text == "" {
+			continue
+		}
+
+		labels, rest := splitLabels(text)
+		if keyword := firstField(rest); strings.ToUpper(keyword) == "EQU" {
+			definition := strings.TrimSpace(rest[len(keyword):])
+			switch {
+			case len(labels) == 0 && current == "":
+				a.errorf(lineNum, keyword, "EQU without a name")
+			case len(labels) == 0:
+				a.equs[current] += "\n" + definition
+			default:
+				// Every label on the line names the definition
+				current = ""
+				for _, name := range labels {
+					if _, ok := a.equs[name]; ok {
+						a.errorf(lineNum, name, "EQU %s defined twice", name)
+						continue
+					}
+					a.equs[name] = definition
+					current = name
+				}
+			}
+			continue
+		}
+
+		current = ""
+		body = append(body, sourceLine{text: text, line: lineNum})
+	}
+
+	expanded := make([]sourceLine, 0, len(body))
+	for _, line := range body {
+		text, err := a.substitute(line.text, 0)
+		if err != nil {
+			a.errorf(line.line, "", "%v", err)
+			continue
+		}
+		for _, part := range strings.Split(text, "\n") {
+			if part = strings.TrimSpace(part); part != "" {
+				expanded = append(expanded, sourceLine{text: part, line: line.line})
+			}
+		}
+	}
+	return expanded
+}
+
+// substitute replaces the EQU names in text with their definitions,
+// expanding definitions that use other EQUs in turn. Names right after
+// a '.' are modifiers and are left alone.
+func (a *Assembler) substitute(text string, depth int) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		if !isIdentStart(text[i]) || (i > 0 && (isIdentChar(text[i-1]) || text[i-1] == '.')) {
+			out.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		name := identAt(text, i)
+		i += len(name)
+
+		definition, ok := a.equs[name]
+		if !ok {
+			out.WriteString(name)
+			continue
+		}
+		if depth >= maxEQUDepth {
+			return "", fmt.Errorf("EQU %s refers to itself", name)
+		}
+		expanded, err := a.substitute(definition, depth+1)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(expanded)
+	}
+	return out.String(), nil
+}
+
+// expandLoops unrolls FOR/ROF blocks. "FOR count" repeats the lines up
+// to the matching ROF count times; "name FOR count" also names a counter
+// that runs from 1 to count. In the repeated lines "&name" becomes the
+// counter as two digits, so that "bomb&name" makes the labels bomb01,
+// bomb02 and so on, and a plain "name" becomes its value. Loops may be
+// nested, and an inner count may use the counters of outer loops.
+// curLine is the number of instructions before the first of the lines.
+func (a *Assembler) expandLoops(lines []sourceLine, curLine int) []sourceLine {
+	var out []sourceLine
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		counter, countExpr, isLoop := parseFor(line.text)
+		if !isLoop {
+			if keyword := firstField(line.text); strings.ToUpper(keyword) == "ROF" {
+				a.errorf(line.line, keyword, "ROF without FOR")
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+
+		// Find the matching ROF
+		end, depth := i+1, 1
+		for ; end < len(lines); end++ {
+			if _, _, nested := parseFor(lines[end].text); nested {
+				depth++
+			} else if strings.ToUpper(firstField(lines[end].text)) == "ROF" {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if end == len(lines) {
+			a.errorf(line.line, "", "FOR without ROF")
+			return out
+		}
+
+		count, err := evaluate(countExpr, func(name string) (int, bool) {
+			return a.predefined(name, curLine+instructionCount(out))
+		})
+		if err != nil {
+			a.errorf(line.line, countExpr, "invalid FOR count: %v", err)
+			count = 0
+		}
+
+		body := lines[i+1 : end]
+		for n := 1; n <= count; n++ {
+			iteration := body
+			if counter != "" {
+				iteration = make([]sourceLine, len(body))
+				for j, line := range body {
+					iteration[j] = sourceLine{text: substituteCounter(line.text, counter, n), line: line.line}
+				}
+			}
+			out = append(out, a.expandLoops(iteration, curLine+instructionCount(out))...)
+		}
+		i = end
+	}
+	return out
+}
+
+// instructionCount returns the number of lines that assemble to an
+// instruction
+func instructionCount(lines []sourceLine) int {
+	count := 0
+	for _, line := range lines {
+		_, text := splitLabels(line.text)
+		switch strings.ToUpper(firstField(text)) {
+		case "", "ORG", "END", "PIN", "FOR", "ROF":
+		default:
+			count++
+		}
+	}
+	return count
+}
+
+// parseFor recognizes a FOR line, returning the counter name (empty if
+// there is none) and the count expression. When several labels precede
+// FOR, the last one names the counter.
+func parseFor(line string) (counter, count string, ok bool) {
+	labels, rest := splitLabels(line)
+	keyword := firstField(rest)
+	if strings.ToUpper(keyword) != "FOR" {
+		return "", "", false
+	}
+	if len(labels) > 0 {
+		counter = labels[len(labels)-1]
+	}
+	return counter, strings.TrimSpace(rest[len(keyword):]), true
+}
+
+// substituteCounter replaces a loop counter in a line: "&name" becomes
+// the counter as two digits and a plain "name" becomes its value
+func substituteCounter(line, name string, value int) string {
+	var out strings.Builder
+	for i := 0; i < len(line); {
+		// "&name" concatenates the two-digit counter
+		if line[i] == '&' && identAt(line, i+1) == name {
+			fmt.Fprintf(&out, "%02d", value)
+			i += 1 + len(name)
+			continue
+		}
+
+		if !isIdentStart(line[i]) || (i > 0 && (isIdentChar(line[i-1]) || line[i-1] == '.')) {
+			out.WriteByte(line[i])
+			i++
+			continue
+		}
+
+		word := identAt(line, i)
+		if word == name {
+			fmt.Fprintf(&out, "%d", value)
+		} else {
+			out.WriteString(word)
+		}
+		i += len(word)
+	}
+	return out.String()
+}
+
+// identAt returns the label or symbol name starting at index i of s, or
+// "" if none starts there
+func identAt(s string, i int) string {
+	if i >= len(s) || !isIdentStart(s[i]) {
+		return ""
+	}
+	j := i
+	for j < len(s) && isIdentChar(s[j]) {
+		j++
+	}
+	return s[i:j]
+}
+
+// firstField returns the first whitespace-separated field of a line
+func firstField(line string) string {
+	if fields := strings.Fields(line); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// stripComment removes an inline comment from a line
+func stripComment(line string) string {
+	if idx := strings.Index(line, ";"); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.TrimSpace(line)
+}
+
+// splitOperands splits an operand list on the commas that are not
+// inside parentheses
+func splitOperands(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	var operands []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				operands = append(operands, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(operands, strings.TrimSpace(s[start:]))
+}
+
+// parseInstruction parses the instruction text of a line, the label
+// removed, reporting any problem against the line. It reports false if
+// the instruction could not be assembled.
+func (a *Assembler) parseInstruction(line sourceLine, text string, currentLine int) (Instruction, bool) {
+	tokens, err := lex(text)
+	if err != nil {
+		a.errorf(line.line, "", "%v", err)
+		return Instruction{}, false
+	}
+	if tokens[0].kind != tokenName {
+		a.errorf(line.line, tokens[0].text, "expected an opcode, found %q", tokens[0].text)
+		return Instruction{}, false
+	}
+
+	// Split the opcode and optional modifier (e.g. MOV.I) from the
+	// operands
+	opToken, modToken, hasMod := tokens[0].text, "", false
+	operandsAt := len(text)
+	if len(tokens) > 2 && tokens[1].text == "." && tokens[2].kind == tokenName {
+		modToken, hasMod = tokens[2].text, true
+		tokens = tokens[3:]
+	} else {
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 {
+		operandsAt = tokens[0].offset
+	}
+	opText := strings.TrimSpace(text[:operandsAt])
+	operands := splitOperands(text[operandsAt:])
+
+	op, err := parseOpCode(opToken)
+	if err != nil {
+		a.errorf(line.line, opToken, "%v", err)
+		return Instruction{}, false
+	}
+
+	inst := Instruction{Op: op}
+
+	// Check the operand count for the instruction type
+	switch {
+	case len(operands) > 2:
+		a.errorf(line.line, opText, "%s has too many operands", opText)
+		return Instruction{}, false
+	case len(operands) == 0 && op != DAT && op != NOP:
+		a.errorf(line.line, opText, "%s requires an operand", opText)
+		return Instruction{}, false
+	case len(operands) < 2 && op != DAT && op != NOP && op != JMP && op != SPL:
+		a.errorf(line.line, opText, "%s requires two operands", opText)
+		return Instruction{}, false
+	}
+
+	ok := true
+	switch {
+	case len(operands) == 1 && op == DAT:
+		// A lone DAT operand is the B operand, and A is #0
+		inst.BMode, inst.B, ok = a.parseOperand(line, operands[0], currentLine)
+	case len(operands) == 1:
+		// A missing B operand is $0
+		inst.AMode, inst.A, ok = a.parseOperand(line, operands[0], currentLine)
+		inst.BMode = DIRECT
+	case len(operands) == 2:
+		var bOK bool
+		inst.AMode, inst.A, ok = a.parseOperand(line, operands[0], currentLine)
+		inst.BMode, inst.B, bOK = a.parseOperand(line, operands[1], currentLine)
+		ok = ok && bOK
+	}
+
+	// Apply the explicit modifier or the ICWS'94 default
+	if hasMod {
+		mod, err := parseModifier(modToken)
+		if err != nil {
+			a.errorf(line.line, opText, "%v", err)
+			return Instruction{}, false
+		}
+		inst.Modifier = mod
+	} else {
+		inst.Modifier = DefaultModifier(inst.Op, inst.AMode, inst.BMode)
+	}
+
+	return inst, ok
+}
+
+// parseOpCode converts string to OpCode
+func parseOpCode(s string) (OpCode, error) {
+	switch strings.ToUpper(s) {
+	case "DAT":
+		return DAT, nil
+	case "MOV":
+		return MOV, nil
+	case "ADD":
+		return ADD, nil
+	case "SUB":
+		return SUB, nil
+	case "JMP":
+		return JMP, nil
+	case "JMZ":
+		return JMZ, nil
+	case "JMN":
+		return JMN, nil
+	case "DJN":
+		return DJN, nil
+	case "CMP":
+		return CMP, nil
+	case "SPL":
+		return SPL, nil
+	case "NOP":
+		return NOP, nil
+	case "MUL":
+		return MUL, nil
+	case "DIV":
+		return DIV, nil
+	case "MOD":
+		return MOD, nil
+	case "SEQ":
+		return SEQ, nil
+	case "SNE":
+		return SNE, nil
+	case "SLT":
+		return SLT, nil
+	case "LDP":
+		return LDP, nil
+	case "STP":
+		return STP, nil
+	default:
+		return DAT, fmt.Errorf("unknown opcode: %s", s)
+	}
+}
+
+// parseModifier converts string to Modifier
+func parseModifier(s string) (Modifier, error) {
+	switch strings.ToUpper(s) {
+	case "A":
+		return ModA, nil
+	case "B":
+		return ModB, nil
+	case "AB":
+		return ModAB, nil
+	case "BA":
+		return ModBA, nil
+	case "F":
+		return ModF, nil
+	case "X":
+		return ModX, nil
+	case "I":
+		return ModI, nil
+	default:
+		return ModF, fmt.Errorf("unknown modifier: %s", s)
+	}
+}
+
+// parseOperand parses an operand into addressing mode and value,
+// reporting any problem against the line. It reports false if the value
+// could not be computed.
+func (a *Assembler) parseOperand(line sourceLine, s string, currentLine int) (AddressMode, int, bool) {
+	s = strings.TrimSpace(s)
+
+	// Default mode
+	mode := DIRECT
+
+	// Check for addressing mode prefix
+	if len(s) > 0 {
+		if prefixed, ok := parseAddressMode(s[0]); ok {
+			mode = prefixed
+			s = s[1:]
+		}
+	}
+
+	// Parse value
+	value, ok := a.evaluateAt(line.line, s, currentLine)
+	if ok && a.config.CoreSize > 0 && (value >= a.config.CoreSize || value <= -a.config.CoreSize) {
+		a.warnf(line.line, s, "value %d wraps around the core of %d cells", value, a.config.CoreSize)
+	}
+
+	return mode, value, ok
+}
+
+// parseAddressMode converts an operand prefix to its addressing mode
+func parseAddressMode(c byte) (AddressMode, bool) {
+	switch c {
+	case '#':
+		return IMMEDIATE, true
+	case '$':
+		return DIRECT, true
+	case '@':
+		return INDIRECT, true
+	case '<':
+		return PREDECREMENT, true
+	case '>':
+		return POSTINCREMENT, true
+	case '*':
+		return A_INDIRECT, true
+	case '{':
+		return A_PREDECREMENT, true
+	case '}':
+		return A_POSTINCREMENT, true
+	}
+	return DIRECT, false
+}
+
+// evaluate computes an expression on the line of instruction
+// currentLine, where each label stands for its offset from that line
+func (a *Assembler) evaluate(expr string, currentLine int) (int, error) {
+	return evaluate(expr, func(name string) (int, bool) {
+		if value, ok := a.predefined(name, currentLine); ok {
+			return value, true
+		}
+		label, ok := a.labels[name]
+		if ok {
+			a.used[name] = true
+		}
+		return label - currentLine, ok
+	})
+}
+
+// evaluateAt evaluates an expression like evaluate, reporting an error
+// against the given source line if it fails
+func (a *Assembler) evaluateAt(line int, expr string, currentLine int) (int, bool) {
+	value, err := a.evaluate(expr, currentLine)
+	if err != nil {
+		// Point at the undefined name rather than the whole expression
+		at := expr
+		var undefined *undefinedError
+		if errors.As(err, &undefined) {
+			at = undefined.name
+		}
+		a.errorf(line, at, "%v", err)
+		return 0, false
+	}
+	return value, true
+}
+
+// predefined returns the value of a predefined constant for the
+// instruction at currentLine. Most describe the settings the warrior
+// will run under.
+func (a *Assembler) predefined(name string, currentLine int) (int, bool) {
+	switch name {
+	case "CORESIZE":
+		return a.config.CoreSize, true
+	case "MAXPROCESSES":
+		return a.config.MaxProcesses, true
+	case "MAXCYCLES":
+		return a.config.MaxCycles, true
+	case "MAXLENGTH":
+		return a.config.MaxLength, true
+	case "MINDISTANCE":
+		return a.config.MinDistance, true
+	case "WARRIORS":
+		return a.config.Warriors, true
+	case "ROUNDS":
+		return a.config.Rounds, true
+	case "PSPACESIZE":
+		return a.config.PSpaceSize, true
+	case "CURLINE":
+		return currentLine, true
+	case "VERSION":
+		return Version, true
+	}
+	return 0, false
+}
+
+// LoadWarriorFromSource creates a warrior from Redcode source, assembled
+// for the settings in config
+func LoadWarriorFromSource(name, source string, color WarriorColor, config Config) (*Warrior, error) {
+	return assembleWarrior(name, "", source, color, config)
+}
+
+// assembleWarrior assembles a warrior from the source read from file,
+// which names the source in diagnostics
+func assembleWarrior(name, file, source string, color WarriorColor, config Config) (*Warrior, error) {
+	assembler := NewAssembler(config)
+	assembler.SetFile(file)
+	instructions, err := assembler.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("warrior %s:\n%w", name, err)
+	}
+
+	warrior := &Warrior{
+		Name:        name,
+		Code:        instructions,
+		StartOffset: assembler.Origin(),
+		Color:       color,
+		Warnings:    assembler.Diagnostics().Warnings(),
+	}
+	warrior.PIN, warrior.HasPIN = assembler.PIN()
+	return warrior, nil
+}
@@ -0,0 +1,201 @@
+package mars
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// assemble assembles source with the default settings and returns its
+// instructions in load file notation
+func assemble(t *testing.T, source string) []string {
+	t.Helper()
+	assembler := NewAssembler(DefaultConfig())
+	code, err := assembler.Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make([]string, len(code))
+	for i, inst := range code {
+		lines[i] = inst.String()
+	}
+	return lines
+}
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"modifiers", "MOV.AB #1, @2\nADD.X 1, 2",
+			[]string{"MOV.AB #1, @2", "ADD.X $1, $2"}},
+		{"default modifiers", "MOV 0, 1\nADD #4, 3\nCMP 1, 2\nJMZ 1, #0",
+			[]string{"MOV.I $0, $1", "ADD.AB #4, $3", "CMP.I $1, $2", "JMZ.B $1, #0"}},
+		{"new opcodes", "MUL 1, 2\nSEQ 1, 2\nSNE 1, 2\nSLT #1, 2\nDIV.X #2, 3\nMOD.A 2, 3",
+			[]string{"MUL.F $1, $2", "SEQ.I $1, $2", "SNE.I $1, $2", "SLT.AB #1, $2", "DIV.X #2, $3", "MOD.A $2, $3"}},
+		{"A-field modes", "MOV *1, {2\nJMP }0",
+			[]string{"MOV.I *1, {2", "JMP.B }0, $0"}},
+		{"label without colon", "loop ADD #4, count\n JMP loop\ncount DAT 5",
+			[]string{"ADD.AB #4, $2", "JMP.B $-1, $0", "DAT.F #0, $5"}},
+		{"several labels", "a b: c MOV a, b\n JMP c",
+			[]string{"MOV.I $0, $0", "JMP.B $-1, $0"}},
+		{"label on its own line", "top:\n MOV 0, 1\n JMP top",
+			[]string{"MOV.I $0, $1", "JMP.B $-1, $0"}},
+		{"colon in comment", "MOV 0, 1 ; note: an imp",
+			[]string{"MOV.I $0, $1"}},
+		{"case insensitive", "mov.ab #1, @2\nSpl 0",
+			[]string{"MOV.AB #1, @2", "SPL.B $0, $0"}},
+		{"label with colon", "loop: ADD #4, count\n JMP loop\ncount: DAT 5",
+			[]string{"ADD.AB #4, $2", "JMP.B $-1, $0", "DAT.F #0, $5"}},
+		{"NOP", "NOP\nJMP -1",
+			[]string{"NOP.F #0, #0", "JMP.B $-1, $0"}},
+		{"lone DAT operand", "DAT #5",
+			[]string{"DAT.F #0, #5"}},
+		{"JMP and SPL operands", "JMP 0, >3\nSPL 1",
+			[]string{"JMP.B $0, >3", "SPL.B $1, $0"}},
+		{"operands without spaces", "MOV 0,1\nDAT #1 , #2",
+			[]string{"MOV.I $0, $1", "DAT.F #1, #2"}},
+		{"stops at END", "JMP 0\nEND\nDAT 1",
+			[]string{"JMP.B $0, $0"}},
+		{"precedence", "DAT #2+3*4, #(2+3)*4\nDAT #0 == 1 < 2, #7 % 4 - 1\nDAT #1 || 0 && 0, #-3",
+			[]string{"DAT.F #14, #20", "DAT.F #0, #2", "DAT.F #1, #-3"}},
+		{"EQU", "step EQU 4\nspan EQU step*2+1\nADD #span, 1",
+			[]string{"ADD.AB #9, $1"}},
+		{"multi-line EQU", "pair EQU MOV 0, 1\n EQU JMP -1\npair\npair",
+			[]string{"MOV.I $0, $1", "JMP.B $-1, $0", "MOV.I $0, $1", "JMP.B $-1, $0"}},
+		{"predefined constants", "DAT #CORESIZE-1, #MAXPROCESSES",
+			[]string{"DAT.F #7999, #8000"}},
+		{"FOR counter", "i FOR 3\ndecoy&i DAT #i, #decoy01\nROF",
+			[]string{"DAT.F #1, #0", "DAT.F #2, #-1", "DAT.F #3, #-2"}},
+		{"nested FOR", "i FOR 2\nj FOR i\nDAT #i, #j\nROF\nROF",
+			[]string{"DAT.F #1, #1", "DAT.F #2, #1", "DAT.F #2, #2"}},
+		{"FOR with EQU count", "n EQU 2\nFOR n\nNOP\nROF",
+			[]string{"NOP.F #0, #0", "NOP.F #0, #0"}},
+		{"passing assert", ";assert CORESIZE == 8000\nJMP 0",
+			[]string{"JMP.B $0, $0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := assemble(t, tt.source); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembleOrigin(t *testing.T) {
+	tests := []struct {
+		source string
+		want   int
+	}{
+		{"MOV 0, 1", 0},
+		{"ORG start\nDAT 0\nstart JMP 0", 1},
+		{"DAT 0\nstart JMP 0\nEND start", 1},
+		{"ORG 1\nDAT 0\nJMP 0\nEND", 1},
+	}
+	for _, tt := range tests {
+		assembler := NewAssembler(DefaultConfig())
+		if _, err := assembler.Parse(tt.source); err != nil {
+			t.Fatalf("%q: %v", tt.source, err)
+		}
+		if got := assembler.Origin(); got != tt.want {
+			t.Errorf("%q: origin %d, want %d", tt.source, got, tt.want)
+		}
+	}
+}
+
+// diagnostic is the part of a Diagnostic the tests check
+type diagnostic struct {
+	severity     Severity
+	line, column int
+	message      string // Start of the message
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []diagnostic
+	}{
+		{"undefined label", "JMP 0\nMOV 0, nowhere",
+			[]diagnostic{{SeverityError, 2, 8, "undefined label: nowhere"}}},
+		{"unknown opcode", "; comment\n\n  bogus 1",
+			[]diagnostic{{SeverityError, 3, 9, "expected an opcode"}, {SeverityWarning, 3, 3, "label bogus is never used"}}},
+		{"every line reported", "MOV 0, nowhere\nDAT #1/0\nJMP 0, elsewhere",
+			[]diagnostic{
+				{SeverityError, 1, 8, "undefined label: nowhere"},
+				{SeverityError, 2, 6, "division by zero"},
+				{SeverityError, 3, 8, "undefined label: elsewhere"},
+			}},
+		{"failed assert", "JMP 0\n;assert CORESIZE == 800",
+			[]diagnostic{{SeverityError, 2, 9, "assertion failed: CORESIZE == 800"}}},
+		{"unused label", "MOV 0, 1\nspare DAT 0",
+			[]diagnostic{{SeverityWarning, 2, 1, "label spare is never used"}}},
+		// The label only appears in a comment, so it must not be placed there
+		{"label in FOR and comment", "i FOR 2\ndecoy&i: DAT #i ; decoy01\nROF",
+			[]diagnostic{
+				{SeverityWarning, 2, 1, "label decoy01 is never used"},
+				{SeverityWarning, 2, 1, "label decoy02 is never used"},
+			}},
+		{"empty source", "",
+			[]diagnostic{{SeverityError, 1, 1, "no instructions"}}},
+		{"only comments", ";name Nothing\n\n  ; still nothing",
+			[]diagnostic{{SeverityError, 1, 1, "no instructions"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assembler := NewAssembler(DefaultConfig())
+			assembler.Parse(tt.source)
+
+			var got []diagnostic
+			for _, d := range assembler.Diagnostics() {
+				got = append(got, diagnostic{d.Severity, d.Line, d.Column, d.Message})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].severity != want.severity || got[i].line != want.line ||
+					got[i].column != want.column || !strings.HasPrefix(got[i].message, want.message) {
+					t.Errorf("diagnostic %d = %v, want %v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAssembleFailure(t *testing.T) {
+	_, err := LoadWarriorFromSource("broken", "MOV 0, nowhere", Red, DefaultConfig())
+	if err == nil {
+		t.Fatal("assembled a warrior with an undefined label")
+	}
+	if !strings.Contains(err.Error(), "1:8: error: undefined label: nowhere") {
+		t.Errorf("error %q does not locate the label", err)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"MOV 0", "MOV requires two operands"},
+		{"JMP", "JMP requires an operand"},
+		{"MOV 0, 1, 2", "MOV has too many operands"},
+		{"MOV.Q 0, 1", "unknown modifier: Q"},
+		{"FOO 0, 1", "expected an opcode"},
+		{"ORG\nJMP 0", "ORG requires a start address"},
+		{"FOR 2\nJMP 0", "FOR without ROF"},
+		{"JMP 0\nROF", "ROF without FOR"},
+		{"JMP 0\n;assert CORESIZE == 800", "assertion failed: CORESIZE == 800"},
+		{"x EQU 1\nx EQU 2\nDAT x", "EQU x defined twice"},
+		{"loop EQU loop+1\nDAT loop", "EQU loop refers to itself"},
+	}
+	for _, tt := range tests {
+		_, err := NewAssembler(DefaultConfig()).Parse(tt.source)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %v, want %q", tt.source, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,710 @@
+package mars
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// BattleStats tracks statistics for a battle
+type BattleStats struct {
+	StartTime       time.Time
+	EndTime         time.Time
+	TotalCycles     int
+	MaxProcesses    map[*Warrior]int
+	InstructionsRun map[*Warrior]int
+	StartPositions  map[*Warrior]int
+	Seed            int64 // Seed that reproduces the warrior placement
+	Survivors       []*Warrior
+	Winner          *Warrior
+	IsDraw          bool
+}
+
+// BattleManager manages battles between warriors
+type BattleManager struct {
+	core     *Core
+	vm       *VM
+	warriors []*Warrior
+	stats    *BattleStats
+	config   Config
+	pspaces  *PSpaces
+	seed     int64
+	seeded   bool
+
+	// Per-warrior counters in load order, copied into the stats maps by
+	// Stats and at the end of the battle so RunCycle does no map updates
+	instructionsRun []int
+	maxProcesses    []int
+}
+
+// NewBattleManager creates a new battle manager using the given
+// simulation parameters
+func NewBattleManager(config Config) *BattleManager {
+	return &BattleManager{
+		config: config,
+	}
+}
+
+// SetSeed fixes the seed used to place warriors, so that a battle can be
+// replayed exactly. Without it every battle draws a seed from the clock.
+func (bm *BattleManager) SetSeed(seed int64) {
+	bm.seed = seed
+	bm.seeded = true
+}
+
+// SetPSpaces sets the P-spaces used by subsequent battles. Pass the same
+// P-spaces to every round of a match so that warriors can carry values
+// from one round to the next; without it every battle starts with fresh
+// P-spaces.
+func (bm *BattleManager) SetPSpaces(pspaces *PSpaces) {
+	bm.pspaces = pspaces
+}
+
+// SetupBattle prepares a new battle
+func (bm *BattleManager) SetupBattle(warriors []*Warrior) error {
+	if err := bm.config.Validate(); err != nil {
+		return err
+	}
+	if len(warriors) == 0 {
+		return fmt.Errorf("no warriors to battle")
+	}
+	if err := checkDistinct(warriors); err != nil {
+		return err
+	}
+	if len(warriors)*bm.config.MinDistance > bm.config.CoreSize {
+		return fmt.Errorf("%d warriors do not fit in a core of %d cells with min distance %d",
+			len(warriors), bm.config.CoreSize, bm.config.MinDistance)
+	}
+	for _, warrior := range warriors {
+		if len(warrior.Code) > bm.config.MaxLength {
+			return fmt.Errorf("warrior %s has %d instructions, more than the max length of %d",
+				warrior.Name, len(warrior.Code), bm.config.MaxLength)
+		}
+	}
+
+	bm.core = NewCore(bm.config.CoreSize)
+	bm.vm = NewVM(bm.core, bm.config)
+	if bm.pspaces != nil {
+		bm.vm.SetPSpaces(bm.pspaces)
+	}
+	bm.warriors = warriors
+	seed := bm.seed
+	if !bm.seeded {
+		seed = time.Now().UnixNano()
+	}
+	bm.stats = &BattleStats{
+		StartTime:       time.Now(),
+		MaxProcesses:    make(map[*Warrior]int),
+		InstructionsRun: make(map[*Warrior]int),
+		StartPositions:  make(map[*Warrior]int),
+		Seed:            seed,
+	}
+
+	bm.instructionsRun = make([]int, len(warriors))
+	bm.maxProcesses = make([]int, len(warriors))
+
+	// Place warriors at random, at least MinDistance apart
+	positions := placeWarriors(len(warriors), bm.config, rand.New(rand.NewSource(seed)))
+	for i, warrior := range warriors {
+		bm.loadWarriorAt(warrior, positions[i])
+		bm.maxProcesses[i] = 1
+	}
+	bm.syncStats()
+
+	return nil
+}
+
+// checkDistinct returns an error if a warrior is listed more than once.
+// Process queues and statistics are kept per warrior, so a warrior
+// loaded twice would run as one.
+func checkDistinct(warriors []*Warrior) error {
+	for i, warrior := range warriors {
+		for _, other := range warriors[:i] {
+			if other == warrior {
+				return fmt.Errorf("warrior %s is listed more than once", warrior.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// placeWarriors chooses start addresses for n warriors the way pMARS
+// does: the first warrior is loaded at address 0 and every other warrior
+// at a random address whose distance to all the others, in both
+// directions around the core, is at least MinDistance
+func placeWarriors(n int, config Config, rng *rand.Rand) []int {
+	positions := make([]int, n)
+	if n < 2 {
+		return positions
+	}
+
+	// Two warriors: the second goes anywhere in the free span
+	span := config.CoreSize - 2*config.MinDistance + 1
+	if n == 2 {
+		positions[1] = config.MinDistance + rng.Intn(span)
+		return positions
+	}
+
+	// More warriors: draw positions and retry on conflicts
+	const maxAttempts = 1000
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		placed := 1
+		for placed < n {
+			candidate := config.MinDistance + rng.Intn(span)
+			if !tooClose(candidate, positions[:placed], config) {
+				positions[placed] = candidate
+				placed++
+				continue
+			}
+			break
+		}
+		if placed == n {
+			return positions
+		}
+	}
+
+	// The core is too crowded for random draws to succeed; space the
+	// warriors evenly and share out the slack at random
+	slack := config.CoreSize - n*config.MinDistance
+	offset := 0
+	for i := 1; i < n; i++ {
+		step := 0
+		if slack > 0 {
+			step = rng.Intn(slack/(n-i) + 1)
+			slack -= step
+		}
+		offset += config.MinDistance + step
+		positions[i] = offset
+	}
+	return positions
+}
+
+// tooClose reports whether addr lies within MinDistance of any of the
+// given positions
+func tooClose(addr int, positions []int, config Config) bool {
+	for _, p := range positions {
+		distance := addr - p
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < config.MinDistance || config.CoreSize-distance < config.MinDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// loadWarriorAt loads a warrior at a specific position
+func (bm *BattleManager) loadWarriorAt(warrior *Warrior, position int) {
+	bm.stats.StartPositions[warrior] = position
+
+	// Copy warrior code to core
+	for i, inst := range warrior.Code {
+		bm.core.Load(position+i, inst, warrior)
+	}
+
+	// Add initial process at the warrior's entry point
+	bm.vm.AddProcess(warrior, position+warrior.StartOffset)
+}
+
+// Core returns the core of the current battle
+func (bm *BattleManager) Core() *Core {
+	return bm.core
+}
+
+// VM returns the virtual machine of the current battle
+func (bm *BattleManager) VM() *VM {
+	return bm.vm
+}
+
+// Stats returns the statistics of the current battle
+func (bm *BattleManager) Stats() *BattleStats {
+	bm.syncStats()
+	return bm.stats
+}
+
+// syncStats copies the per-warrior counters into the stats maps
+func (bm *BattleManager) syncStats() {
+	for i, warrior := range bm.warriors {
+		bm.stats.InstructionsRun[warrior] = bm.instructionsRun[i]
+		bm.stats.MaxProcesses[warrior] = bm.maxProcesses[i]
+	}
+}
+
+// RunCycle executes one cycle and updates statistics
+func (bm *BattleManager) RunCycle() bool {
+	if bm.stats.TotalCycles >= bm.config.MaxCycles {
+		bm.stats.IsDraw = true
+		bm.finish()
+		return false
+	}
+
+	// Every warrior with a live process executes one instruction this
+	// cycle
+	for i, warrior := range bm.warriors {
+		if bm.vm.ProcessCount(warrior) > 0 {
+			bm.instructionsRun[i]++
+		}
+	}
+
+	// Execute cycle
+	bm.vm.ExecuteCycle()
+	bm.stats.TotalCycles++
+
+	// Update max processes
+	for i, warrior := range bm.warriors {
+		if count := bm.vm.ProcessCount(warrior); count > bm.maxProcesses[i] {
+			bm.maxProcesses[i] = count
+		}
+	}
+
+	// Check for winner
+	if bm.vm.LiveWarriors() <= 1 {
+		for _, warrior := range bm.warriors {
+			if bm.vm.IsWarriorAlive(warrior) {
+				bm.stats.Winner = warrior
+			}
+		}
+		if bm.stats.Winner == nil {
+			bm.stats.IsDraw = true
+		}
+		bm.finish()
+		return false
+	}
+
+	return true
+}
+
+// finish records the end of the battle in the statistics and in each
+// warrior's P-space
+func (bm *BattleManager) finish() {
+	bm.stats.EndTime = time.Now()
+	bm.syncStats()
+	for _, warrior := range bm.warriors {
+		if bm.vm.IsWarriorAlive(warrior) {
+			bm.stats.Survivors = append(bm.stats.Survivors, warrior)
+		}
+	}
+	bm.vm.pspaces.RecordResult(bm.warriors, bm.vm.IsWarriorAlive)
+}
+
+// GetBattleReport generates a battle report
+func (bm *BattleManager) GetBattleReport() string {
+	duration := bm.stats.EndTime.Sub(bm.stats.StartTime)
+
+	report := fmt.Sprintf("=== BATTLE REPORT ===\n")
+	report += fmt.Sprintf("Duration: %v\n", duration)
+	report += fmt.Sprintf("Total Cycles: %d\n", bm.stats.TotalCycles)
+	report += fmt.Sprintf("Core Size: %d, Max Cycles: %d, Max Processes: %d\n",
+		bm.config.CoreSize, bm.config.MaxCycles, bm.config.MaxProcesses)
+	report += fmt.Sprintf("Rules: %s\n", RuleSetString(bm.config.Rules))
+	report += fmt.Sprintf("Seed: %d\n", bm.stats.Seed)
+	report += fmt.Sprintf("\n")
+
+	if bm.stats.IsDraw {
+		report += "Result: DRAW\n"
+	} else if bm.stats.Winner != nil {
+		report += fmt.Sprintf("Winner: %s by %s\n", bm.stats.Winner.Name, bm.stats.Winner.Author)
+	}
+
+	report += fmt.Sprintf("\nWarrior Statistics:\n")
+	for _, warrior := range bm.warriors {
+		report += fmt.Sprintf("\n%s:\n", warrior.Name)
+		report += fmt.Sprintf("  Max Processes: %d\n", bm.stats.MaxProcesses[warrior])
+		report += fmt.Sprintf("  Instructions Run: %d\n", bm.stats.InstructionsRun[warrior])
+		report += fmt.Sprintf("  Starting Position: %d\n", bm.stats.StartPositions[warrior])
+
+		// Calculate efficiency
+		if bm.stats.InstructionsRun[warrior] > 0 {
+			efficiency := float64(bm.stats.InstructionsRun[warrior]) / float64(bm.stats.TotalCycles)
+			report += fmt.Sprintf("  Efficiency: %.2f%%\n", efficiency*100)
+		}
+	}
+
+	return report
+}
+
+// WarriorResult holds a warrior's record in a tournament
+type WarriorResult struct {
+	Wins   int // Rounds survived alone
+	Losses int // Rounds in which the warrior died
+	Ties   int // Rounds survived along with other warriors
+	Score  int // Sum over survived rounds of (W*W-1)/S
+}
+
+// RoundResult records the outcome of one tournament round
+type RoundResult struct {
+	Warriors  []*Warrior // Warriors in load and execution order
+	Survivors []*Warrior
+	Seed      int64 // Placement seed, for replaying the round with SetSeed
+	Cycles    int
+}
+
+// Tournament runs a tournament between multiple warriors. By default
+// every pair of warriors fights a match of several rounds; in melee mode
+// all warriors share the core in every round.
+//
+// Each round scores (W*W-1)/S for every survivor, where W is the number
+// of warriors in the round and S the number of survivors. As in pMARS the
+// division rounds down, so a one-on-one win is worth 3 and a draw 1 to
+// each side.
+type Tournament struct {
+	warriors     []*Warrior
+	rounds       int
+	config       Config
+	seed         int64
+	melee        bool
+	workers      int
+	results      map[*Warrior]*WarriorResult
+	headToHead   map[*Warrior]map[*Warrior]*WarriorResult
+	history      []RoundResult
+	draws        int
+	totalBattles int
+}
+
+// NewTournament creates a new tournament between distinct warriors
+func NewTournament(warriors []*Warrior, rounds int, config Config) (*Tournament, error) {
+	if err := checkDistinct(warriors); err != nil {
+		return nil, err
+	}
+	results := make(map[*Warrior]*WarriorResult)
+	headToHead := make(map[*Warrior]map[*Warrior]*WarriorResult)
+	for _, warrior := range warriors {
+		results[warrior] = &WarriorResult{}
+		headToHead[warrior] = make(map[*Warrior]*WarriorResult)
+		for _, opponent := range warriors {
+			if opponent != warrior {
+				headToHead[warrior][opponent] = &WarriorResult{}
+			}
+		}
+	}
+	return &Tournament{
+		warriors:   warriors,
+		rounds:     rounds,
+		config:     config,
+		seed:       time.Now().UnixNano(),
+		workers:    runtime.NumCPU(),
+		results:    results,
+		headToHead: headToHead,
+	}, nil
+}
+
+// SetMelee selects melee mode, in which all warriors fight in one core
+// every round instead of in pairs
+func (t *Tournament) SetMelee(melee bool) {
+	t.melee = melee
+}
+
+// SetSeed fixes the seed from which the placement seed of every round is
+// drawn, so that the whole tournament can be replayed
+func (t *Tournament) SetSeed(seed int64) {
+	t.seed = seed
+}
+
+// SetWorkers sets how many matches run at the same time. Values below 1
+// use one worker per CPU.
+func (t *Tournament) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	t.workers = workers
+}
+
+// Seed returns the seed from which round placements are drawn
+func (t *Tournament) Seed() int64 {
+	return t.seed
+}
+
+// Result returns a warrior's record in the tournament
+func (t *Tournament) Result(warrior *Warrior) WarriorResult {
+	if result, ok := t.results[warrior]; ok {
+		return *result
+	}
+	return WarriorResult{}
+}
+
+// HeadToHead returns a warrior's record against one opponent, counted
+// over the rounds both took part in. A round is a win when the warrior
+// survived and the opponent did not, and a tie when both survived or both
+// died. The score is only kept for one-on-one matches.
+func (t *Tournament) HeadToHead(warrior, opponent *Warrior) WarriorResult {
+	if result, ok := t.headToHead[warrior][opponent]; ok {
+		return *result
+	}
+	return WarriorResult{}
+}
+
+// Rounds returns the outcome of every round in the order they ran
+func (t *Tournament) Rounds() []RoundResult {
+	return t.history
+}
+
+// Run executes the tournament. Matches are spread over the workers and
+// their rounds recorded in schedule order, so the results for a given
+// seed do not depend on the number of workers.
+func (t *Tournament) Run() error {
+	matches := t.schedule()
+
+	workers := t.workers
+	if workers > len(matches) {
+		workers = len(matches)
+	}
+	errs := make([]error, len(matches))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range next {
+				errs[m] = t.runMatch(matches[m])
+			}
+		}()
+	}
+	for m := range matches {
+		next <- m
+	}
+	close(next)
+	wg.Wait()
+
+	for m, match := range matches {
+		if errs[m] != nil {
+			return errs[m]
+		}
+		for _, round := range match {
+			t.record(round)
+		}
+	}
+
+	return nil
+}
+
+// schedule lays out every round of the tournament with its warriors and
+// placement seed, grouped into matches. The rounds of a match share
+// P-space and must run in sequence; when no warrior uses P-space every
+// round is a match of its own, so that all of them can run in parallel.
+func (t *Tournament) schedule() [][]RoundResult {
+	// Each round gets its own placement seed drawn in a fixed order
+	rng := rand.New(rand.NewSource(t.seed))
+	var matches [][]RoundResult
+
+	if t.melee {
+		// Every round has all warriors in the core, rotating which
+		// warrior is loaded and executes first
+		n := len(t.warriors)
+		match := make([]RoundResult, t.rounds)
+		for round := range match {
+			order := make([]*Warrior, n)
+			for i := range order {
+				order[i] = t.warriors[(round+i)%n]
+			}
+			match[round] = RoundResult{Warriors: order, Seed: rng.Int63()}
+		}
+		matches = append(matches, match)
+	} else {
+		// Round-robin: each warrior fights each other warrior
+		for i := 0; i < len(t.warriors); i++ {
+			for j := i + 1; j < len(t.warriors); j++ {
+				w1, w2 := t.warriors[i], t.warriors[j]
+				match := make([]RoundResult, t.rounds)
+				for round := range match {
+					// Alternate starting positions
+					order := []*Warrior{w1, w2}
+					if round%2 == 1 {
+						order = []*Warrior{w2, w1}
+					}
+					match[round] = RoundResult{Warriors: order, Seed: rng.Int63()}
+				}
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	if usesPSpace(t.warriors) {
+		return matches
+	}
+	var rounds [][]RoundResult
+	for _, match := range matches {
+		for i := range match {
+			rounds = append(rounds, match[i:i+1])
+		}
+	}
+	return rounds
+}
+
+// usesPSpace reports whether any of the warriors has an LDP or STP
+func usesPSpace(warriors []*Warrior) bool {
+	for _, warrior := range warriors {
+		for _, inst := range warrior.Code {
+			if inst.Op == LDP || inst.Op == STP {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runMatch fights the rounds of a match in order, filling in their
+// outcomes. P-space persists across the rounds of a match.
+func (t *Tournament) runMatch(rounds []RoundResult) error {
+	pspaces := NewPSpaces(t.config.PSpaceSize)
+
+	for i := range rounds {
+		round := &rounds[i]
+		bm := NewBattleManager(t.config)
+		bm.SetSeed(round.Seed)
+		bm.SetPSpaces(pspaces)
+		if err := bm.SetupBattle(round.Warriors); err != nil {
+			return err
+		}
+
+		// Run battle to completion
+		for bm.RunCycle() {
+			// Battle continues
+		}
+
+		round.Survivors = bm.stats.Survivors
+		round.Cycles = bm.stats.TotalCycles
+	}
+
+	return nil
+}
+
+// record adds the outcome of a round to the results
+func (t *Tournament) record(round RoundResult) {
+	t.history = append(t.history, round)
+	t.totalBattles++
+
+	if len(round.Survivors) != 1 {
+		t.draws++
+	}
+
+	total := len(round.Warriors)
+	survivors := len(round.Survivors)
+	alive := make(map[*Warrior]bool, survivors)
+	for _, warrior := range round.Survivors {
+		alive[warrior] = true
+	}
+
+	for _, warrior := range round.Warriors {
+		result := t.results[warrior]
+		score := 0
+		switch {
+		case !alive[warrior]:
+			result.Losses++
+		case survivors == 1:
+			result.Wins++
+		default:
+			result.Ties++
+		}
+		if alive[warrior] {
+			score = (total*total - 1) / survivors
+			result.Score += score
+		}
+
+		for _, opponent := range round.Warriors {
+			if opponent == warrior {
+				continue
+			}
+			pair := t.headToHead[warrior][opponent]
+			switch {
+			case alive[warrior] && !alive[opponent]:
+				pair.Wins++
+			case !alive[warrior] && alive[opponent]:
+				pair.Losses++
+			default:
+				pair.Ties++
+			}
+			if total == 2 {
+				pair.Score += score
+			}
+		}
+	}
+}
+
+// WriteResults writes the tournament results to w: the rankings by
+// score, followed by the head-to-head matrix
+func (t *Tournament) WriteResults(w io.Writer) {
+	fmt.Fprintln(w, "=== TOURNAMENT RESULTS ===")
+	fmt.Fprintf(w, "Total Battles: %d\n", t.totalBattles)
+	fmt.Fprintf(w, "Draws: %d (%.1f%%)\n", t.draws, float64(t.draws)/float64(t.totalBattles)*100)
+
+	fmt.Fprintln(w, "\nWarrior Rankings:")
+
+	// Sort warriors by score, keeping the original order on ties
+	ranked := make([]*Warrior, len(t.warriors))
+	copy(ranked, t.warriors)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return t.results[ranked[i]].Score > t.results[ranked[j]].Score
+	})
+
+	// Display rankings
+	for i, warrior := range ranked {
+		result := t.results[warrior]
+		fmt.Fprintf(w, "%d. %s: score %d (%d wins, %d losses, %d ties)\n",
+			i+1, warrior.Name, result.Score, result.Wins, result.Losses, result.Ties)
+	}
+
+	// Head-to-head matrix, rows and columns in ranking order
+	if t.melee {
+		fmt.Fprintln(w, "\nHead-to-Head (row vs column: wins/losses/ties):")
+	} else {
+		fmt.Fprintln(w, "\nHead-to-Head (row vs column: score, wins/losses/ties):")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "\t")
+	for i := range ranked {
+		fmt.Fprintf(tw, "%d\t", i+1)
+	}
+	fmt.Fprintln(tw)
+	for i, warrior := range ranked {
+		fmt.Fprintf(tw, "%d. %s\t", i+1, warrior.Name)
+		for _, opponent := range ranked {
+			if opponent == warrior {
+				fmt.Fprint(tw, "-\t")
+				continue
+			}
+			pair := t.headToHead[warrior][opponent]
+			if t.melee {
+				fmt.Fprintf(tw, "%d/%d/%d\t", pair.Wins, pair.Losses, pair.Ties)
+			} else {
+				fmt.Fprintf(tw, "%d %d/%d/%d\t", pair.Score, pair.Wins, pair.Losses, pair.Ties)
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// WriteRounds writes the outcome, seed and length of every round to w
+func (t *Tournament) WriteRounds(w io.Writer) {
+	fmt.Fprintln(w, "=== ROUNDS ===")
+	for i, round := range t.history {
+		names := make([]string, len(round.Warriors))
+		for j, warrior := range round.Warriors {
+			names[j] = warrior.Name
+		}
+
+		outcome := "no survivors"
+		switch len(round.Survivors) {
+		case 0:
+		case 1:
+			outcome = "won by " + round.Survivors[0].Name
+		default:
+			survivors := make([]string, len(round.Survivors))
+			for j, warrior := range round.Survivors {
+				survivors[j] = warrior.Name
+			}
+			outcome = "tie between " + strings.Join(survivors, ", ")
+		}
+
+		fmt.Fprintf(w, "%d. %s: %s after %d cycles (seed %d)\n",
+			i+1, strings.Join(names, " vs "), outcome, round.Cycles, round.Seed)
+	}
+}
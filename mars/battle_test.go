@@ -0,0 +1,186 @@
+package mars
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDuplicateWarriors(t *testing.T) {
+	// A warrior listed twice would share one process queue with itself
+	imp := CreateImp()
+	warriors := []*Warrior{imp, CreateDwarf(), imp}
+
+	if err := NewBattleManager(DefaultConfig()).SetupBattle(warriors); err == nil {
+		t.Errorf("SetupBattle accepted a warrior twice")
+	}
+	if _, err := NewTournament(warriors, 1, DefaultConfig()); err == nil {
+		t.Errorf("NewTournament accepted a warrior twice")
+	}
+
+	// Separate copies of the same warrior are distinct warriors
+	if err := NewBattleManager(DefaultConfig()).SetupBattle([]*Warrior{CreateImp(), CreateImp()}); err != nil {
+		t.Errorf("SetupBattle rejected two copies of a warrior: %v", err)
+	}
+}
+
+func TestPlaceWarriors(t *testing.T) {
+	crowded := testConfig()
+	crowded.MinDistance = 10
+	tests := []struct {
+		name   string
+		config Config
+		n      int
+	}{
+		{"two warriors", DefaultConfig(), 2},
+		{"melee", DefaultConfig(), 8},
+		{"nano melee", testConfig(), 10},
+		// Only every tenth address fits, which random draws do not find
+		{"crowded", crowded, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 200; seed++ {
+				positions := placeWarriors(tt.n, tt.config, rand.New(rand.NewSource(seed)))
+				checkPlacement(t, positions, tt.config)
+
+				again := placeWarriors(tt.n, tt.config, rand.New(rand.NewSource(seed)))
+				if !slices.Equal(positions, again) {
+					t.Fatalf("seed %d: placed at %v, then at %v", seed, positions, again)
+				}
+			}
+		})
+	}
+
+	positions := placeWarriors(8, crowded, rand.New(rand.NewSource(1)))
+	if want := []int{0, 10, 20, 30, 40, 50, 60, 70}; !slices.Equal(positions, want) {
+		t.Errorf("crowded core: placed at %v, want %v", positions, want)
+	}
+}
+
+// checkPlacement fails the test unless the first warrior starts at 0 and
+// every pair of warriors is at least MinDistance apart in both directions
+// around the core
+func checkPlacement(t *testing.T, positions []int, config Config) {
+	t.Helper()
+	if positions[0] != 0 {
+		t.Fatalf("first warrior placed at %d, want 0", positions[0])
+	}
+	for i, p := range positions {
+		if p < 0 || p >= config.CoreSize {
+			t.Fatalf("warrior %d placed at %d, outside the core", i, p)
+		}
+		for j, q := range positions[:i] {
+			forward := (p - q + config.CoreSize) % config.CoreSize
+			backward := config.CoreSize - forward
+			if forward < config.MinDistance || backward < config.MinDistance {
+				t.Fatalf("warriors %d and %d placed at %d and %d, closer than %d",
+					j, i, q, p, config.MinDistance)
+			}
+		}
+	}
+}
+
+func TestTournamentScoring(t *testing.T) {
+	tests := []struct {
+		name      string
+		warriors  int
+		survivors []int // Indices of the surviving warriors
+		want      []WarriorResult
+		pairs     map[[2]int]WarriorResult // Head-to-head cells by warrior and opponent index
+	}{
+		{"one-on-one win", 2, []int{0},
+			[]WarriorResult{{Wins: 1, Score: 3}, {Losses: 1}},
+			map[[2]int]WarriorResult{{0, 1}: {Wins: 1, Score: 3}, {1, 0}: {Losses: 1}}},
+		// 3/2 rounds down, as in pMARS
+		{"one-on-one draw", 2, []int{0, 1},
+			[]WarriorResult{{Ties: 1, Score: 1}, {Ties: 1, Score: 1}},
+			map[[2]int]WarriorResult{{0, 1}: {Ties: 1, Score: 1}, {1, 0}: {Ties: 1, Score: 1}}},
+		// Head-to-head scores are only kept for one-on-one rounds
+		{"melee win", 3, []int{1},
+			[]WarriorResult{{Losses: 1}, {Wins: 1, Score: 8}, {Losses: 1}},
+			map[[2]int]WarriorResult{{1, 0}: {Wins: 1}, {0, 1}: {Losses: 1}, {0, 2}: {Ties: 1}}},
+		{"melee with two survivors", 3, []int{0, 2},
+			[]WarriorResult{{Ties: 1, Score: 4}, {Losses: 1}, {Ties: 1, Score: 4}},
+			map[[2]int]WarriorResult{{0, 2}: {Ties: 1}, {2, 1}: {Wins: 1}, {1, 0}: {Losses: 1}}},
+		{"melee draw", 3, []int{0, 1, 2},
+			[]WarriorResult{{Ties: 1, Score: 2}, {Ties: 1, Score: 2}, {Ties: 1, Score: 2}},
+			map[[2]int]WarriorResult{{0, 1}: {Ties: 1}, {2, 0}: {Ties: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warriors := make([]*Warrior, tt.warriors)
+			for i := range warriors {
+				warriors[i] = CreateImp()
+			}
+			var survivors []*Warrior
+			for _, i := range tt.survivors {
+				survivors = append(survivors, warriors[i])
+			}
+
+			tournament, err := NewTournament(warriors, 1, DefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tournament.record(RoundResult{Warriors: warriors, Survivors: survivors})
+			for i, want := range tt.want {
+				if got := tournament.Result(warriors[i]); got != want {
+					t.Errorf("warrior %d: %+v, want %+v", i, got, want)
+				}
+			}
+			for pair, want := range tt.pairs {
+				if got := tournament.HeadToHead(warriors[pair[0]], warriors[pair[1]]); got != want {
+					t.Errorf("warrior %d against %d: %+v, want %+v", pair[0], pair[1], got, want)
+				}
+			}
+			if got := len(tournament.Rounds()); got != 1 {
+				t.Errorf("%d rounds recorded, want 1", got)
+			}
+		})
+	}
+}
+
+func TestTournamentWorkers(t *testing.T) {
+	// The P-space warrior makes the rounds of each match run in sequence;
+	// the other warriors alone let every round run on its own
+	pspace, err := LoadWarriorFromSource("counter", "LDP.AB #0, $3\nADD.AB #1, $2\nSTP.B $1, #0\nJMP $0\nDAT #0, #0", Green, testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string][]*Warrior{
+		"without P-space": {CreateImp(), CreateDwarf(), CreateStone()},
+		"with P-space":    {CreateImp(), CreateDwarf(), pspace},
+	}
+	for name, warriors := range fields {
+		for _, melee := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, melee %v", name, melee), func(t *testing.T) {
+				var tournaments []*Tournament
+				for _, workers := range []int{1, 8} {
+					tournament, err := NewTournament(warriors, 6, testConfig())
+					if err != nil {
+						t.Fatal(err)
+					}
+					tournament.SetSeed(42)
+					tournament.SetMelee(melee)
+					tournament.SetWorkers(workers)
+					if err := tournament.Run(); err != nil {
+						t.Fatal(err)
+					}
+					tournaments = append(tournaments, tournament)
+				}
+
+				serial, parallel := tournaments[0], tournaments[1]
+				if !reflect.DeepEqual(serial.Rounds(), parallel.Rounds()) {
+					t.Errorf("rounds differ between 1 and 8 workers")
+				}
+				for _, warrior := range warriors {
+					if got, want := parallel.Result(warrior), serial.Result(warrior); got != want {
+						t.Errorf("%s: %+v with 8 workers, %+v with 1", warrior.Name, got, want)
+					}
+				}
+			})
+		}
+	}
+}
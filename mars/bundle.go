@@ -0,0 +1,213 @@
+package mars
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BundleManifest is the name of the file that describes a bundle
+const BundleManifest = "bundle.json"
+
+// Bundle is a set of warriors shared as one directory or tar archive,
+// such as a benchmark. Its manifest describes the set and lists the
+// warrior files, relative to the manifest:
+//
+//	{
+//		"name": "Classics",
+//		"description": "Warriors every entry should beat",
+//		"author": "Core War Community",
+//		"preset": "94nop",
+//		"warriors": [
+//			{"file": "imp.red"},
+//			{"file": "stones.red", "name": "Stone"}
+//		]
+//	}
+//
+// An entry loads every warrior of its file, or with a name only the
+// warrior called so.
+type Bundle struct {
+	Name        string
+	Description string
+	Author      string
+	Preset      string // Hill settings the warriors were written for, if given
+	Warriors    []*Warrior
+}
+
+// bundleManifest is the JSON form of a bundle manifest
+type bundleManifest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Preset      string `json:"preset"`
+	Warriors    []struct {
+		File string `json:"file"`
+		Name string `json:"name"`
+	} `json:"warriors"`
+}
+
+// LoadBundle loads a bundle from a directory or a tar archive, which
+// may be gzipped, and assembles its warriors for the settings in config.
+// An archive holds the manifest at its root or in a single top-level
+// directory. Bundles without a name in the manifest are named after
+// the path.
+func LoadBundle(bundlePath string, config Config) (*Bundle, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	readFile := func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(bundlePath, filepath.FromSlash(name)))
+	}
+	if !info.IsDir() {
+		if readFile, err = readTarFiles(bundlePath); err != nil {
+			return nil, fmt.Errorf("%s: %v", bundlePath, err)
+		}
+	}
+
+	bundle, err := loadBundle(bundlePath, readFile, config)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", bundlePath, err)
+	}
+	if bundle.Name == "" {
+		base := filepath.Base(bundlePath)
+		for _, ext := range []string{".gz", ".tgz", ".tar"} {
+			base = strings.TrimSuffix(base, ext)
+		}
+		bundle.Name = base
+	}
+	return bundle, nil
+}
+
+// loadBundle reads the manifest of a bundle with readFile and loads the
+// warriors it lists, coloring them in turn
+func loadBundle(bundlePath string, readFile func(name string) ([]byte, error), config Config) (*Bundle, error) {
+	data, err := readFile(BundleManifest)
+	if err != nil {
+		return nil, err
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %v", BundleManifest, err)
+	}
+	if len(manifest.Warriors) == 0 {
+		return nil, fmt.Errorf("%s lists no warriors", BundleManifest)
+	}
+
+	bundle := &Bundle{
+		Name:        manifest.Name,
+		Description: manifest.Description,
+		Author:      manifest.Author,
+		Preset:      manifest.Preset,
+	}
+	for _, entry := range manifest.Warriors {
+		// Keep files inside the bundle
+		if !fs.ValidPath(entry.File) {
+			return nil, fmt.Errorf("invalid warrior file: %q", entry.File)
+		}
+		source, err := readFile(entry.File)
+		if err != nil {
+			return nil, err
+		}
+
+		file := filepath.Join(bundlePath, filepath.FromSlash(entry.File))
+		warriors, err := assembleWarriors(file, strings.TrimSuffix(path.Base(entry.File), ".red"), string(source), config)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Name != "" {
+			var selected *Warrior
+			for _, warrior := range warriors {
+				if warrior.Name == entry.Name {
+					selected = warrior
+					break
+				}
+			}
+			if selected == nil {
+				return nil, fmt.Errorf("%s has no warrior named %s", entry.File, entry.Name)
+			}
+			warriors = []*Warrior{selected}
+		}
+		bundle.Warriors = append(bundle.Warriors, warriors...)
+	}
+
+	for i, warrior := range bundle.Warriors {
+		warrior.Color = warriorColors[i%len(warriorColors)]
+	}
+	return bundle, nil
+}
+
+// readTarFiles reads the regular files of a tar archive, gzipped or
+// not, and returns a function that reads them by their path relative
+// to the manifest
+func readTarFiles(filename string) (func(name string) ([]byte, error), error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewReader(file)
+	var r io.Reader = buffered
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	files := make(map[string][]byte)
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(header.Name)] = data
+	}
+
+	// The manifest sits at the root or in a single top-level directory
+	root := ""
+	if _, ok := files[BundleManifest]; !ok {
+		var roots []string
+		for name := range files {
+			if dir, base := path.Split(name); base == BundleManifest && strings.Count(dir, "/") == 1 {
+				roots = append(roots, dir)
+			}
+		}
+		if len(roots) != 1 {
+			return nil, fmt.Errorf("no %s at the root of the archive", BundleManifest)
+		}
+		root = roots[0]
+	}
+
+	return func(name string) ([]byte, error) {
+		data, ok := files[root+name]
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return data, nil
+	}, nil
+}
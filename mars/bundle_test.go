@@ -0,0 +1,148 @@
+package mars
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// bundleFiles is a bundle that takes one warrior of a file of two
+var bundleFiles = map[string]string{
+	BundleManifest: `{
+		"name": "Classics",
+		"preset": "94nop",
+		"warriors": [
+			{"file": "imp.red"},
+			{"file": "stones/pair.red", "name": "Stone"}
+		]
+	}`,
+	"imp.red":         ";name Imp\nMOV 0, 1",
+	"stones/pair.red": ";redcode\n;name Dwarf\nADD #4, 3\nMOV 2, @2\nJMP -2\nDAT 0\n;redcode\n;name Stone\nMOV <2, 3\nADD 3, -1\nJMP -2\nDAT 0",
+}
+
+// writeBundleDir writes files into a new directory and returns its path
+func writeBundleDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// writeBundleTar writes files into a tar archive, gzipped if the name
+// ends in .gz, with every path under root
+func writeBundleTar(t *testing.T, name, root string, files map[string]string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	if strings.HasSuffix(name, ".gz") {
+		gz := gzip.NewWriter(file)
+		defer gz.Close()
+		w = gz
+	}
+	archive := tar.NewWriter(w)
+	defer archive.Close()
+
+	for path, content := range files {
+		header := &tar.Header{Name: root + path, Mode: 0o644, Size: int64(len(content))}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(archive, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filename
+}
+
+// checkBundle fails the test if bundle is not the one in bundleFiles
+func checkBundle(t *testing.T, bundle *Bundle) {
+	t.Helper()
+	if bundle.Name != "Classics" || bundle.Preset != "94nop" {
+		t.Errorf("bundle %q for preset %q, want Classics for 94nop", bundle.Name, bundle.Preset)
+	}
+	var names []string
+	for _, warrior := range bundle.Warriors {
+		names = append(names, warrior.Name)
+	}
+	if want := []string{"Imp", "Stone"}; !slices.Equal(names, want) {
+		t.Errorf("warriors %q, want %q", names, want)
+	}
+}
+
+func TestLoadBundle(t *testing.T) {
+	tests := []struct {
+		name string
+		path func(t *testing.T) string
+	}{
+		{"directory", func(t *testing.T) string {
+			return writeBundleDir(t, bundleFiles)
+		}},
+		{"tar", func(t *testing.T) string {
+			return writeBundleTar(t, "classics.tar", "", bundleFiles)
+		}},
+		{"tar.gz in a top-level directory", func(t *testing.T) string {
+			return writeBundleTar(t, "classics.tar.gz", "classics/", bundleFiles)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := LoadBundle(tt.path(t), DefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkBundle(t, bundle)
+		})
+	}
+}
+
+func TestLoadBundleErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"outside the bundle", `{"warriors": [{"file": "../imp.red"}]}`, "invalid warrior file"},
+		{"unknown name", `{"warriors": [{"file": "stones/pair.red", "name": "Mice"}]}`, "no warrior named Mice"},
+		{"missing file", `{"warriors": [{"file": "mice.red"}]}`, "mice.red"},
+		{"no warriors", `{"name": "Empty"}`, "lists no warriors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{BundleManifest: tt.manifest}
+			for name, content := range bundleFiles {
+				if name != BundleManifest {
+					files[name] = content
+				}
+			}
+			dir := writeBundleDir(t, files)
+			// A file next to the bundle, which it must not reach
+			if err := os.WriteFile(filepath.Join(filepath.Dir(dir), "imp.red"), []byte("MOV 0, 1"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadBundle(dir, DefaultConfig())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package mars
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Config holds the simulation parameters for a battle
+type Config struct {
+	CoreSize     int     // Number of cells in the core
+	MaxCycles    int     // Cycles before a battle is declared a draw
+	MaxProcesses int     // Maximum processes per warrior
+	MaxLength    int     // Maximum instructions per warrior
+	MinDistance  int     // Minimum distance between warrior start addresses
+	ReadLimit    int     // Size of the window around the executing instruction that it can read
+	WriteLimit   int     // Size of the window around the executing instruction that it can write
+	PSpaceSize   int     // Number of cells in each warrior's P-space
+	Rules        RuleSet // Execution rule set
+
+	// Seen by warriors as WARRIORS and ROUNDS when they are assembled
+	Warriors int // Warriors in each battle
+	Rounds   int // Rounds in each match
+}
+
+// presets holds the settings of the standard hills
+var presets = map[string]Config{
+	"94nop": {
+		CoreSize:     8000,
+		MaxCycles:    80000,
+		MaxProcesses: 8000,
+		MaxLength:    100,
+		MinDistance:  100,
+		ReadLimit:    8000,
+		WriteLimit:   8000,
+		PSpaceSize:   500,
+		Warriors:     2,
+		Rounds:       1,
+	},
+	"tiny": {
+		CoreSize:     800,
+		MaxCycles:    8000,
+		MaxProcesses: 800,
+		MaxLength:    20,
+		MinDistance:  20,
+		ReadLimit:    800,
+		WriteLimit:   800,
+		PSpaceSize:   50,
+		Warriors:     2,
+		Rounds:       1,
+	},
+	"nano": {
+		CoreSize:     80,
+		MaxCycles:    800,
+		MaxProcesses: 80,
+		MaxLength:    5,
+		MinDistance:  5,
+		ReadLimit:    80,
+		WriteLimit:   80,
+		PSpaceSize:   5,
+		Warriors:     2,
+		Rounds:       1,
+	},
+	"lp": {
+		CoreSize:     8000,
+		MaxCycles:    80000,
+		MaxProcesses: 8,
+		MaxLength:    200,
+		MinDistance:  200,
+		ReadLimit:    8000,
+		WriteLimit:   8000,
+		PSpaceSize:   500,
+		Warriors:     2,
+		Rounds:       1,
+	},
+}
+
+// DefaultConfig returns the settings of the standard '94 no-pspace hill
+func DefaultConfig() Config {
+	return presets["94nop"]
+}
+
+// PresetConfig returns the settings of a named hill preset
+func PresetConfig(name string) (Config, error) {
+	config, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown preset: %s (available: %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	return config, nil
+}
+
+// PresetNames returns the names of all hill presets in sorted order
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that the parameters describe a runnable simulation
+func (c Config) Validate() error {
+	if c.CoreSize <= 0 {
+		return fmt.Errorf("core size must be positive, got %d", c.CoreSize)
+	}
+	if c.MaxCycles <= 0 {
+		return fmt.Errorf("max cycles must be positive, got %d", c.MaxCycles)
+	}
+	if c.MaxProcesses <= 0 {
+		return fmt.Errorf("max processes must be positive, got %d", c.MaxProcesses)
+	}
+	if c.MaxLength <= 0 || c.MaxLength > c.CoreSize {
+		return fmt.Errorf("max length must be between 1 and %d, got %d", c.CoreSize, c.MaxLength)
+	}
+	if c.MinDistance < c.MaxLength || c.MinDistance > c.CoreSize {
+		return fmt.Errorf("min distance must be between %d and %d, got %d", c.MaxLength, c.CoreSize, c.MinDistance)
+	}
+	if c.PSpaceSize <= 0 {
+		return fmt.Errorf("P-space size must be positive, got %d", c.PSpaceSize)
+	}
+	if c.ReadLimit <= 0 || c.CoreSize%c.ReadLimit != 0 {
+		return fmt.Errorf("read limit must be a divisor of the core size %d, got %d", c.CoreSize, c.ReadLimit)
+	}
+	if c.WriteLimit <= 0 || c.CoreSize%c.WriteLimit != 0 {
+		return fmt.Errorf("write limit must be a divisor of the core size %d, got %d", c.CoreSize, c.WriteLimit)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+// Package mars implements a Core War simulator (Memory Array Redcode
+// Simulator): the core, the virtual machine, the Redcode assembler, and
+// battle and tournament management. It has no display dependencies so
+// it can be embedded in headless tools.
+package mars
+
+import "fmt"
+
+// OpCode represents the instruction operation codes
+type OpCode int
+
+const (
+	DAT OpCode = iota // Data (kills process)
+	MOV               // Move
+	ADD               // Add
+	SUB               // Subtract
+	JMP               // Jump
+	JMZ               // Jump if Zero
+	JMN               // Jump if Not Zero
+	DJN               // Decrement and Jump if Not Zero
+	CMP               // Compare (skip if equal)
+	SPL               // Split (create new process)
+	NOP               // No operation
+	MUL               // Multiply
+	DIV               // Divide (kills process on division by zero)
+	MOD               // Modulus (kills process on division by zero)
+	SEQ               // Skip if equal
+	SNE               // Skip if not equal
+	SLT               // Skip if less than
+	LDP               // Load from P-space
+	STP               // Store to P-space
+)
+
+// AddressMode represents the addressing modes
+type AddressMode int
+
+const (
+	IMMEDIATE       AddressMode = iota // #
+	DIRECT                             // $
+	INDIRECT                           // @
+	PREDECREMENT                       // <
+	POSTINCREMENT                      // >
+	A_INDIRECT                         // *
+	A_PREDECREMENT                     // {
+	A_POSTINCREMENT                    // }
+)
+
+// Modifier selects which fields of the source and destination an opcode uses
+type Modifier int
+
+const (
+	ModA  Modifier = iota // .A  - A field to A field
+	ModB                  // .B  - B field to B field
+	ModAB                 // .AB - A field to B field
+	ModBA                 // .BA - B field to A field
+	ModF                  // .F  - A to A and B to B
+	ModX                  // .X  - A to B and B to A
+	ModI                  // .I  - whole instruction
+)
+
+// Instruction represents a single Core War instruction
+type Instruction struct {
+	Op       OpCode
+	Modifier Modifier
+	AMode    AddressMode
+	BMode    AddressMode
+	A        int
+	B        int
+}
+
+// WarriorColor represents the color assigned to a warrior
+type WarriorColor int
+
+const (
+	Empty WarriorColor = iota
+	Red
+	Blue
+	Green
+	Yellow
+)
+
+// Core represents the memory core where warriors battle
+type Core struct {
+	cells       []Instruction
+	owners      []WarriorColor // Color of the warrior that last wrote each cell, for display
+	writers     []*Warrior     // Warrior that last wrote each cell, for the ownership rules
+	readEffect  []float32      // Visual effect for read operations
+	writeEffect []float32      // Visual effect for write operations
+	execEffect  []float32      // Visual effect for execution
+	size        int
+}
+
+// NewCore creates a new memory core
+func NewCore(size int) *Core {
+	c := &Core{
+		cells:       make([]Instruction, size),
+		owners:      make([]WarriorColor, size),
+		writers:     make([]*Warrior, size),
+		readEffect:  make([]float32, size),
+		writeEffect: make([]float32, size),
+		execEffect:  make([]float32, size),
+		size:        size,
+	}
+
+	// Initialize all cells with DAT #0, #0
+	for i := 0; i < size; i++ {
+		c.cells[i] = Instruction{
+			Op:       DAT,
+			Modifier: ModF,
+			AMode:    IMMEDIATE,
+			BMode:    IMMEDIATE,
+			A:        0,
+			B:        0,
+		}
+		c.owners[i] = Empty
+	}
+
+	return c
+}
+
+// Size returns the number of cells in the core
+func (c *Core) Size() int {
+	return c.size
+}
+
+// Cell returns the instruction at the given address without marking a
+// read, for display and inspection
+func (c *Core) Cell(addr int) Instruction {
+	return c.cells[c.normalize(addr)]
+}
+
+// Owner returns the color of the warrior that last wrote the given
+// address
+func (c *Core) Owner(addr int) WarriorColor {
+	return c.owners[c.normalize(addr)]
+}
+
+// Effects returns the read, write and execution effect intensities of
+// the given address, each between 0 and 1
+func (c *Core) Effects(addr int) (read, write, exec float32) {
+	addr = c.normalize(addr)
+	return c.readEffect[addr], c.writeEffect[addr], c.execEffect[addr]
+}
+
+// Load places an instruction at the given address for a warrior being
+// loaded, storing its fields in the 0..size-1 range so that comparisons
+// against computed values behave. Loading does not mark a write.
+func (c *Core) Load(addr int, inst Instruction, warrior *Warrior) {
+	addr = c.normalize(addr)
+	inst.A = c.normalize(inst.A)
+	inst.B = c.normalize(inst.B)
+	c.cells[addr] = inst
+	c.owners[addr] = warrior.Color
+	c.writers[addr] = warrior
+}
+
+// Read returns the instruction at the given address
+func (c *Core) Read(addr int) Instruction {
+	addr = c.normalize(addr)
+	c.readEffect[addr] = 1.0
+	return c.cells[addr]
+}
+
+// Write stores an instruction at the given address on behalf of a
+// warrior
+func (c *Core) Write(addr int, inst Instruction, warrior *Warrior) {
+	addr = c.normalize(addr)
+	c.cells[addr] = inst
+	c.owners[addr] = warrior.Color
+	c.writers[addr] = warrior
+	c.writeEffect[addr] = 1.0
+}
+
+// adjustA adds delta to the A field at the given address without
+// changing the cell's owner
+func (c *Core) adjustA(addr int, delta int) {
+	addr = c.normalize(addr)
+	c.cells[addr].A = c.normalize(c.cells[addr].A + delta)
+	c.writeEffect[addr] = 1.0
+}
+
+// adjustB adds delta to the B field at the given address without
+// changing the cell's owner
+func (c *Core) adjustB(addr int, delta int) {
+	addr = c.normalize(addr)
+	c.cells[addr].B = c.normalize(c.cells[addr].B + delta)
+	c.writeEffect[addr] = 1.0
+}
+
+// Execute marks a cell as being executed
+func (c *Core) Execute(addr int) {
+	addr = c.normalize(addr)
+	c.execEffect[addr] = 1.0
+}
+
+// normalize ensures address is within core bounds
+func (c *Core) normalize(addr int) int {
+	addr = addr % c.size
+	if addr < 0 {
+		addr += c.size
+	}
+	return addr
+}
+
+// DecayEffects reduces the intensity of visual effects over time
+func (c *Core) DecayEffects() {
+	decay := float32(0.95)
+	for i := range c.cells {
+		c.readEffect[i] *= decay
+		c.writeEffect[i] *= decay
+		c.execEffect[i] *= decay
+
+		// Clamp to zero if too small
+		if c.readEffect[i] < 0.01 {
+			c.readEffect[i] = 0
+		}
+		if c.writeEffect[i] < 0.01 {
+			c.writeEffect[i] = 0
+		}
+		if c.execEffect[i] < 0.01 {
+			c.execEffect[i] = 0
+		}
+	}
+}
+
+// OpCodeString returns the string representation of an opcode
+func OpCodeString(op OpCode) string {
+	switch op {
+	case DAT:
+		return "DAT"
+	case MOV:
+		return "MOV"
+	case ADD:
+		return "ADD"
+	case SUB:
+		return "SUB"
+	case JMP:
+		return "JMP"
+	case JMZ:
+		return "JMZ"
+	case JMN:
+		return "JMN"
+	case DJN:
+		return "DJN"
+	case CMP:
+		return "CMP"
+	case SPL:
+		return "SPL"
+	case NOP:
+		return "NOP"
+	case MUL:
+		return "MUL"
+	case DIV:
+		return "DIV"
+	case MOD:
+		return "MOD"
+	case SEQ:
+		return "SEQ"
+	case SNE:
+		return "SNE"
+	case SLT:
+		return "SLT"
+	case LDP:
+		return "LDP"
+	case STP:
+		return "STP"
+	default:
+		return "???"
+	}
+}
+
+// ModifierString returns the string representation of a modifier
+func ModifierString(mod Modifier) string {
+	switch mod {
+	case ModA:
+		return "A"
+	case ModB:
+		return "B"
+	case ModAB:
+		return "AB"
+	case ModBA:
+		return "BA"
+	case ModF:
+		return "F"
+	case ModX:
+		return "X"
+	case ModI:
+		return "I"
+	default:
+		return "?"
+	}
+}
+
+// DefaultModifier returns the ICWS'94 modifier for an instruction
+// written without one
+func DefaultModifier(op OpCode, aMode, bMode AddressMode) Modifier {
+	switch op {
+	case DAT, NOP:
+		return ModF
+	case MOV, CMP, SEQ, SNE:
+		if aMode == IMMEDIATE {
+			return ModAB
+		}
+		if bMode == IMMEDIATE {
+			return ModB
+		}
+		return ModI
+	case ADD, SUB, MUL, DIV, MOD:
+		if aMode == IMMEDIATE {
+			return ModAB
+		}
+		if bMode == IMMEDIATE {
+			return ModB
+		}
+		return ModF
+	case SLT, LDP, STP:
+		if aMode == IMMEDIATE {
+			return ModAB
+		}
+		return ModB
+	default:
+		// JMP, JMZ, JMN, DJN, SPL
+		return ModB
+	}
+}
+
+// AddressModeString returns the string representation of an address mode
+func AddressModeString(mode AddressMode) string {
+	switch mode {
+	case IMMEDIATE:
+		return "#"
+	case DIRECT:
+		return "$"
+	case INDIRECT:
+		return "@"
+	case PREDECREMENT:
+		return "<"
+	case POSTINCREMENT:
+		return ">"
+	case A_INDIRECT:
+		return "*"
+	case A_PREDECREMENT:
+		return "{"
+	case A_POSTINCREMENT:
+		return "}"
+	default:
+		return "?"
+	}
+}
+
+// String formats an instruction in canonical Redcode, with the modifier
+// and both operands spelled out, e.g. "MOV.I $0, $1"
+func (inst Instruction) String() string {
+	return fmt.Sprintf("%s.%s %s%d, %s%d",
+		OpCodeString(inst.Op), ModifierString(inst.Modifier),
+		AddressModeString(inst.AMode), inst.A,
+		AddressModeString(inst.BMode), inst.B)
+}
@@ -0,0 +1,94 @@
+package mars
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity tells whether a diagnostic stops a warrior from assembling
+type Severity int
+
+const (
+	SeverityError   Severity = iota // The source cannot be assembled
+	SeverityWarning                 // The source assembles but is probably wrong
+)
+
+// String returns the string representation of a severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "???"
+	}
+}
+
+// Diagnostic is a problem the assembler found in a source line
+type Diagnostic struct {
+	Severity Severity
+	File     string // Source file name, if known
+	Line     int    // 1-based line in the source
+	Column   int    // 1-based byte column in the line
+	Message  string
+	Source   string // Text of the source line
+}
+
+// String formats the diagnostic as "file:line:column: severity: message"
+// followed by the source line and a caret under the column
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	if d.File != "" {
+		b.WriteString(d.File + ":")
+	}
+	fmt.Fprintf(&b, "%d:%d: %s: %s", d.Line, d.Column, d.Severity, d.Message)
+
+	if d.Source != "" {
+		b.WriteString("\n" + d.Source + "\n")
+		// Keep the tabs of the source so the caret lines up
+		for i := 0; i < d.Column-1 && i < len(d.Source); i++ {
+			if d.Source[i] == '\t' {
+				b.WriteByte('\t')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('^')
+	}
+	return b.String()
+}
+
+// Diagnostics is the list of problems found in a source, in line order.
+// It is returned as the error of a failed assembly.
+type Diagnostics []Diagnostic
+
+// Error formats every diagnostic of the list
+func (ds Diagnostics) Error() string {
+	messages := make([]string, len(ds))
+	for i, d := range ds {
+		messages[i] = d.String()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// HasErrors reports whether any of the diagnostics is an error
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Warnings returns the diagnostics that are warnings
+func (ds Diagnostics) Warnings() Diagnostics {
+	var warnings Diagnostics
+	for _, d := range ds {
+		if d.Severity == SeverityWarning {
+			warnings = append(warnings, d)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,175 @@
+package mars
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DisassembleOptions controls how instructions are rendered as Redcode
+type DisassembleOptions struct {
+	CoreSize int  // Normalizes operands to -CoreSize/2..CoreSize/2; 0 leaves them as stored
+	Absolute bool // Prefix lines with their address and show operands as addresses
+	Labels   bool // Name the jump targets inside the listing and refer to them by name
+}
+
+// listing renders a sequence of instructions loaded at an address
+type listing struct {
+	code    []Instruction
+	origin  int // Address of code[0]
+	options DisassembleOptions
+	labels  map[int]string // Label of each named instruction, by index
+}
+
+// Disassemble renders code, loaded at address origin, as Redcode with one
+// instruction per line.
+//
+// Relative listings without labels assemble back to the same code.
+// Absolute listings are meant for reading: their operands show the
+// addresses they refer to rather than offsets.
+func Disassemble(code []Instruction, origin int, options DisassembleOptions) string {
+	l := newListing(code, origin, options)
+	return l.render()
+}
+
+// DisassembleCore renders count cells of core starting at address start.
+// Operands are normalized to the core size unless the options give one.
+func DisassembleCore(core *Core, start, count int, options DisassembleOptions) string {
+	if options.CoreSize == 0 {
+		options.CoreSize = core.Size()
+	}
+	code := make([]Instruction, count)
+	for i := range code {
+		code[i] = core.Cell(start + i)
+	}
+	return Disassemble(code, start, options)
+}
+
+// DisassembleWarrior renders a warrior as Redcode source, with its name
+// and author, an ORG for its entry point and END
+func DisassembleWarrior(warrior *Warrior, options DisassembleOptions) string {
+	l := newListing(warrior.Code, 0, options)
+
+	var b strings.Builder
+	b.WriteString(";redcode-94\n")
+	fmt.Fprintf(&b, ";name %s\n", warrior.Name)
+	if warrior.Author != "" {
+		fmt.Fprintf(&b, ";author %s\n", warrior.Author)
+	}
+	if warrior.HasPIN {
+		fmt.Fprintf(&b, "PIN %d\n", warrior.PIN)
+	}
+	if options.Labels && warrior.StartOffset < len(warrior.Code) {
+		fmt.Fprintf(&b, "ORG %s\n", l.label(warrior.StartOffset))
+	} else {
+		fmt.Fprintf(&b, "ORG %d\n", warrior.StartOffset)
+	}
+	b.WriteString(l.render())
+	b.WriteString("END\n")
+	return b.String()
+}
+
+// newListing prepares a listing, naming the jump targets if requested
+func newListing(code []Instruction, origin int, options DisassembleOptions) *listing {
+	l := &listing{
+		code:    code,
+		origin:  origin,
+		options: options,
+		labels:  make(map[int]string),
+	}
+	if options.Labels {
+		for i, inst := range code {
+			switch inst.Op {
+			case JMP, JMZ, JMN, DJN, SPL:
+				if target := i + l.normalize(inst.A); inst.AMode != IMMEDIATE && target >= 0 && target < len(code) {
+					l.label(target)
+				}
+			}
+		}
+	}
+	return l
+}
+
+// label returns the name of the instruction at index i, creating it if
+// needed. Names carry the address, so they stay stable across listings.
+func (l *listing) label(i int) string {
+	name, ok := l.labels[i]
+	if !ok {
+		name = "L" + strconv.Itoa(l.address(i))
+		l.labels[i] = name
+	}
+	return name
+}
+
+// normalize maps a value to -CoreSize/2..CoreSize/2
+func (l *listing) normalize(value int) int {
+	size := l.options.CoreSize
+	if size <= 0 {
+		return value
+	}
+	value %= size
+	if value < 0 {
+		value += size
+	}
+	if value > size/2 {
+		value -= size
+	}
+	return value
+}
+
+// address returns the core address of the instruction at index i
+func (l *listing) address(i int) int {
+	address := l.origin + i
+	if size := l.options.CoreSize; size > 0 {
+		address %= size
+		if address < 0 {
+			address += size
+		}
+	}
+	return address
+}
+
+// render writes every instruction of the listing
+func (l *listing) render() string {
+	labelWidth := 0
+	for _, name := range l.labels {
+		labelWidth = max(labelWidth, len(name))
+	}
+	addressWidth := len(strconv.Itoa(max(l.options.CoreSize-1, l.address(len(l.code)))))
+
+	var b strings.Builder
+	for i, inst := range l.code {
+		if l.options.Absolute {
+			fmt.Fprintf(&b, "%0*d  ", addressWidth, l.address(i))
+		}
+		if l.options.Labels {
+			fmt.Fprintf(&b, "%-*s  ", labelWidth, l.labels[i])
+		}
+		fmt.Fprintf(&b, "%s.%s %s, %s\n",
+			OpCodeString(inst.Op), ModifierString(inst.Modifier),
+			l.operand(i, inst.AMode, inst.A), l.operand(i, inst.BMode, inst.B))
+	}
+	return b.String()
+}
+
+// operand renders an operand of the instruction at index i: by label if
+// it refers to a named instruction, otherwise as an offset or, in
+// absolute listings, as the address it refers to
+func (l *listing) operand(i int, mode AddressMode, value int) string {
+	value = l.normalize(value)
+	if mode == IMMEDIATE {
+		return "#" + strconv.Itoa(value)
+	}
+
+	prefix := AddressModeString(mode)
+	if name, ok := l.labels[i+value]; ok {
+		if mode == DIRECT {
+			prefix = ""
+		}
+		return prefix + name
+	}
+	if l.options.Absolute {
+		return prefix + strconv.Itoa(l.address(i+value))
+	}
+	return prefix + strconv.Itoa(value)
+}
@@ -0,0 +1,82 @@
+package mars
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "warriors", "*.red"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no warriors found")
+	}
+	config := DefaultConfig()
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			warrior, err := LoadWarriorFromFile(file, Red, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			source := DisassembleWarrior(warrior, DisassembleOptions{CoreSize: config.CoreSize})
+			loaded, err := LoadWarriorFromSource(warrior.Name, source, Red, config)
+			if err != nil {
+				t.Fatalf("%v\n%s", err, source)
+			}
+			if !slices.Equal(loaded.Code, warrior.Code) {
+				t.Errorf("code %v, want %v\n%s", loaded.Code, warrior.Code, source)
+			}
+			if loaded.StartOffset != warrior.StartOffset {
+				t.Errorf("start offset %d, want %d", loaded.StartOffset, warrior.StartOffset)
+			}
+		})
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	mice := "start SPL 2\nJMP -1\nMOV 0, 1\nend start"
+	dwarf := "ADD #4, 3\nMOV #0, @2\nJMP -2\nDAT #0, #0"
+	tests := []struct {
+		name   string
+		source string
+		origin int
+		opts   DisassembleOptions
+		want   string
+	}{
+		{"relative", dwarf, 0, DisassembleOptions{},
+			"ADD.AB #4, $3\nMOV.AB #0, @2\nJMP.B $-2, $0\nDAT.F #0, #0\n"},
+		{"labels", mice, 0, DisassembleOptions{Labels: true},
+			"L0  SPL.B L2, L0\n    JMP.B L0, $0\nL2  MOV.I L2, $1\n"},
+		// The listing wraps around the end of the core
+		{"absolute", dwarf, 7998, DisassembleOptions{CoreSize: 8000, Absolute: true},
+			"7998  ADD.AB #4, $1\n7999  MOV.AB #0, @1\n0000  JMP.B $7998, $0\n0001  DAT.F #0, #0\n"},
+		{"absolute labels", mice, 100, DisassembleOptions{CoreSize: 8000, Absolute: true, Labels: true},
+			"0100  L100  SPL.B L102, L100\n0101        JMP.B L100, $101\n0102  L102  MOV.I L102, $103\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := NewAssembler(DefaultConfig()).Parse(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Disassemble(code, tt.origin, tt.opts); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisassembleWarrior(t *testing.T) {
+	warrior, err := LoadWarriorFromSource("Mice", "start SPL 2\nJMP -1\nMOV 0, 1\nend start", Red, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ";redcode-94\n;name Mice\nORG L0\n" +
+		"L0  SPL.B L2, L0\n    JMP.B L0, $0\nL2  MOV.I L2, $1\nEND\n"
+	if got := DisassembleWarrior(warrior, DisassembleOptions{Labels: true}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,228 @@
+package mars
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Redcode expressions follow C: from lowest to highest precedence the
+// binary operators are ||, &&, == !=, < <= > >=, + -, and * / %, with
+// unary - + ! binding tightest. Comparisons and logical operators
+// yield 1 for true and 0 for false.
+
+// undefinedError reports a symbol that is neither a label nor a constant
+type undefinedError struct {
+	name string
+}
+
+func (e *undefinedError) Error() string {
+	return "undefined label: " + e.name
+}
+
+// exprParser evaluates an expression by recursive descent
+type exprParser struct {
+	tokens []string
+	pos    int
+	lookup func(name string) (int, bool) // Value of a symbol, if defined
+}
+
+// evaluate computes the value of a Redcode expression. Symbols are
+// resolved with lookup.
+func evaluate(expr string, lookup func(name string) (int, bool)) (int, error) {
+	lexed, err := lex(expr)
+	if err != nil {
+		return 0, fmt.Errorf("%v in expression %q", err, expr)
+	}
+	if len(lexed) == 0 {
+		return 0, fmt.Errorf("missing expression")
+	}
+
+	tokens := make([]string, len(lexed))
+	for i, t := range lexed {
+		tokens[i] = t.text
+	}
+	p := &exprParser{tokens: tokens, lookup: lookup}
+	value, err := p.parseOr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("unexpected %q in expression %q", p.tokens[p.pos], expr)
+	}
+	return value, nil
+}
+
+// isDigit reports whether c is a decimal digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentStart reports whether c can start a label or symbol name
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentChar reports whether c can appear in a label or symbol name
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// peek returns the next token, or "" at the end of the expression
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// binary parses a left-associative chain of the given operators over
+// operands parsed by next
+func (p *exprParser) binary(next func() (int, error), ops ...string) (int, error) {
+	left, err := next()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		found := false
+		for _, candidate := range ops {
+			if op == candidate {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := next()
+		if err != nil {
+			return 0, err
+		}
+		if left, err = applyOperator(op, left, right); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (p *exprParser) parseOr() (int, error) {
+	return p.binary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (int, error) {
+	return p.binary(p.parseEquality, "&&")
+}
+
+func (p *exprParser) parseEquality() (int, error) {
+	return p.binary(p.parseRelational, "==", "!=")
+}
+
+func (p *exprParser) parseRelational() (int, error) {
+	return p.binary(p.parseSum, "<", "<=", ">", ">=")
+}
+
+func (p *exprParser) parseSum() (int, error) {
+	return p.binary(p.parseProduct, "+", "-")
+}
+
+func (p *exprParser) parseProduct() (int, error) {
+	return p.binary(p.parseUnary, "*", "/", "%")
+}
+
+// parseUnary parses a number, a symbol, a parenthesized expression or
+// a unary operator applied to one of these
+func (p *exprParser) parseUnary() (int, error) {
+	token := p.peek()
+	if token == "" {
+		return 0, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch {
+	case token == "-" || token == "+" || token == "!":
+		value, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch token {
+		case "-":
+			return -value, nil
+		case "!":
+			return boolValue(value == 0), nil
+		}
+		return value, nil
+
+	case token == "(":
+		value, err := p.parseOr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ")" {
+			return 0, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return value, nil
+
+	case isDigit(token[0]):
+		value, err := strconv.Atoi(token)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number: %s", token)
+		}
+		return value, nil
+
+	case isIdentStart(token[0]):
+		value, ok := p.lookup(token)
+		if !ok {
+			return 0, &undefinedError{name: token}
+		}
+		return value, nil
+	}
+
+	return 0, fmt.Errorf("unexpected %q in expression", token)
+}
+
+// applyOperator computes the result of a binary operator
+func applyOperator(op string, left, right int) (int, error) {
+	switch op {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		if op == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "==":
+		return boolValue(left == right), nil
+	case "!=":
+		return boolValue(left != right), nil
+	case "<":
+		return boolValue(left < right), nil
+	case "<=":
+		return boolValue(left <= right), nil
+	case ">":
+		return boolValue(left > right), nil
+	case ">=":
+		return boolValue(left >= right), nil
+	case "&&":
+		return boolValue(left != 0 && right != 0), nil
+	case "||":
+		return boolValue(left != 0 || right != 0), nil
+	}
+	return 0, fmt.Errorf("unknown operator: %s", op)
+}
+
+// boolValue converts a condition to 1 or 0
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package mars
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind classifies the tokens of a Redcode line
+type tokenKind int
+
+const (
+	tokenName     tokenKind = iota // Label, opcode, directive or symbol
+	tokenNumber                    // Decimal number
+	tokenOperator                  // Punctuation or operator
+)
+
+// token is a lexical element of a Redcode line
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int // Byte offset of the token in the line
+}
+
+// twoCharOperators are the operators spelled with two characters
+var twoCharOperators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+// lex splits a line into tokens. A ';' starts a comment that runs to the
+// end of the line and produces no tokens.
+func lex(line string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == ';':
+			return tokens, nil
+		case isDigit(c):
+			j := i
+			for j < len(line) && isDigit(line[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: line[i:j], offset: i})
+			i = j
+		case isIdentStart(c):
+			name := identAt(line, i)
+			tokens = append(tokens, token{kind: tokenName, text: name, offset: i})
+			i += len(name)
+		default:
+			text := string(c)
+			for _, op := range twoCharOperators {
+				if strings.HasPrefix(line[i:], op) {
+					text = op
+					break
+				}
+			}
+			if len(text) == 1 && !strings.ContainsRune("+-*/%()<>!=,:.#$@{}&", rune(c)) {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: text, offset: i})
+			i += len(text)
+		}
+	}
+	return tokens, nil
+}
+
+// keywords are the names that can follow labels at the start of a line:
+// the directives, with the opcodes checked separately
+var keywords = map[string]bool{
+	"ORG": true, "END": true, "PIN": true, "EQU": true, "FOR": true, "ROF": true,
+}
+
+// isKeyword reports whether a name is an opcode or directive rather than
+// a label
+func isKeyword(name string) bool {
+	upper := strings.ToUpper(name)
+	if keywords[upper] {
+		return true
+	}
+	_, err := parseOpCode(upper)
+	return err == nil
+}
+
+// splitLabels separates the labels that start a line from the rest of
+// it. A label is any name that is not an opcode or directive, with or
+// without a trailing ':', and a line may carry several of them.
+func splitLabels(text string) (labels []string, rest string) {
+	tokens, err := lex(text)
+	if err != nil {
+		// Leave the line whole; parsing the rest reports the problem
+		return nil, text
+	}
+
+	i := 0
+	for i < len(tokens) && tokens[i].kind == tokenName && !isKeyword(tokens[i].text) {
+		labels = append(labels, tokens[i].text)
+		i++
+		if i < len(tokens) && tokens[i].text == ":" {
+			i++
+		}
+	}
+	if i == len(tokens) {
+		return labels, ""
+	}
+	return labels, strings.TrimSpace(text[tokens[i].offset:])
+}
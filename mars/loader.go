@@ -0,0 +1,158 @@
+package mars
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// warriorColors are the colors given in turn to warriors loaded together
+var warriorColors = []WarriorColor{Red, Blue, Green, Yellow}
+
+// LoadWarriorFromFile loads the first warrior of a .red file, assembled
+// for the settings in config
+func LoadWarriorFromFile(filename string, color WarriorColor, config Config) (*Warrior, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), ".red")
+	return loadSourceWarrior(filename, splitWarriors(string(content))[0], name, color, config)
+}
+
+// LoadWarriorsFromFile loads every warrior of a .red file, assembled for
+// the settings in config. Each ;redcode line starts a new warrior, and
+// the warriors are colored in turn.
+func LoadWarriorsFromFile(filename string, config Config) ([]*Warrior, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return assembleWarriors(filename, strings.TrimSuffix(filepath.Base(filename), ".red"), string(content), config)
+}
+
+// assembleWarriors assembles every warrior of a file. Unnamed warriors
+// are named after the file, numbered when it holds several.
+func assembleWarriors(file, baseName, source string, config Config) ([]*Warrior, error) {
+	parts := splitWarriors(source)
+	warriors := make([]*Warrior, len(parts))
+	for i, part := range parts {
+		name := baseName
+		if len(parts) > 1 {
+			name = fmt.Sprintf("%s %d", baseName, i+1)
+		}
+		warrior, err := loadSourceWarrior(file, part, name, warriorColors[i%len(warriorColors)], config)
+		if err != nil {
+			return nil, err
+		}
+		warriors[i] = warrior
+	}
+	return warriors, nil
+}
+
+// loadSourceWarrior assembles the source of one warrior, taking its name
+// and author from the metadata comments. Warriors without a ;name
+// comment get defaultName.
+func loadSourceWarrior(file, source, defaultName string, color WarriorColor, config Config) (*Warrior, error) {
+	name := extractName(source)
+	if name == "" {
+		name = defaultName
+	}
+
+	warrior, err := assembleWarrior(name, file, source, color, config)
+	if err != nil {
+		return nil, err
+	}
+
+	warrior.Author = extractAuthor(source)
+	return warrior, nil
+}
+
+// splitWarriors splits a source into the warriors it holds. Each
+// ;redcode line starts a warrior that runs to the next one, and text
+// before the first is ignored, as in pMARS. A source without ;redcode
+// lines is a single warrior.
+func splitWarriors(source string) []string {
+	lines := strings.Split(source, "\n")
+	var starts []int
+	for i, line := range lines {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), ";redcode") {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 {
+		return []string{source}
+	}
+
+	parts := make([]string, len(starts))
+	for i, start := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		// Blank lines stand in for the text before the warrior, so that
+		// diagnostics keep the line numbers of the file
+		parts[i] = strings.Repeat("\n", start) + strings.Join(lines[start:end], "\n")
+	}
+	return parts
+}
+
+// extractName extracts the warrior name from metadata comments
+func extractName(source string) string {
+	lines := strings.Split(source, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, ";name ") {
+			return strings.TrimSpace(line[6:])
+		}
+	}
+	return ""
+}
+
+// extractAuthor extracts the author from metadata comments
+func extractAuthor(source string) string {
+	lines := strings.Split(source, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, ";author ") {
+			return strings.TrimSpace(line[8:])
+		}
+	}
+	return "Unknown"
+}
+
+// LoadAllWarriors loads the warriors of the .red files in dir, assembled
+// for the settings in config. A file that fails to load, for instance
+// because an ;assert does not hold, is skipped and its error returned in
+// failures; err is only set when dir cannot be searched.
+func LoadAllWarriors(dir string, config Config) (warriors []*Warrior, failures []error, err error) {
+	warriors = make([]*Warrior, 0)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.red"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range files {
+		loaded, err := LoadWarriorsFromFile(file, config)
+		if err != nil {
+			// Report the error but continue loading other warriors
+			failures = append(failures, err)
+			continue
+		}
+
+		for _, warrior := range loaded {
+			warrior.Color = warriorColors[len(warriors)%len(warriorColors)]
+			warriors = append(warriors, warrior)
+
+			// Limit to 4 warriors for visualization
+			if len(warriors) >= 4 {
+				return warriors, failures, nil
+			}
+		}
+	}
+
+	return warriors, failures, nil
+}
@@ -0,0 +1,87 @@
+package mars
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAllWarriors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"imp.red":    ";name Imp\nMOV 0, 1",
+		"nano.red":   ";name Nano\n;assert CORESIZE == 80\nMOV 0, 1",
+		"broken.red": "MOV 0",
+		"notes.txt":  "not a warrior",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	warriors, failures, err := LoadAllWarriors(dir, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warriors) != 1 || warriors[0].Name != "Imp" {
+		t.Errorf("loaded %d warriors, want only Imp", len(warriors))
+	}
+	if len(failures) != 2 {
+		t.Fatalf("got failures %v, want broken.red and nano.red", failures)
+	}
+	// Failures come in file name order
+	for i, want := range []string{"requires two operands", "assertion failed"} {
+		if !strings.Contains(failures[i].Error(), want) {
+			t.Errorf("failure %d = %q, want it to mention %q", i, failures[i], want)
+		}
+	}
+}
+
+func TestLoadWarriorsFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pair.red")
+	source := "Text before the first warrior is ignored\n" +
+		";redcode\n;name First\nJMP 0\n" +
+		";redcode\nMOV 0, 1\n"
+	if err := os.WriteFile(file, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	warriors, err := LoadWarriorsFromFile(file, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warriors) != 2 {
+		t.Fatalf("loaded %d warriors, want 2", len(warriors))
+	}
+	// Unnamed warriors are named after the file and numbered
+	for i, want := range []string{"First", "pair 2"} {
+		if warriors[i].Name != want {
+			t.Errorf("warrior %d is named %q, want %q", i, warriors[i].Name, want)
+		}
+		if len(warriors[i].Code) != 1 {
+			t.Errorf("warrior %d has %d instructions, want 1", i, len(warriors[i].Code))
+		}
+	}
+	if warriors[0].Color == warriors[1].Color {
+		t.Error("warriors of one file share a color")
+	}
+}
+
+func TestLoadWarriorsFromFileLines(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pair.red")
+	source := ";redcode\n;name First\nJMP 0\n\n;redcode\n;name Second\nMOV 0, nowhere\n"
+	if err := os.WriteFile(file, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadWarriorsFromFile(file, DefaultConfig())
+	if err == nil {
+		t.Fatal("loaded a warrior with an undefined label")
+	}
+	// The error gives the line in the file, not in the second warrior
+	if want := file + ":7:8: error: undefined label: nowhere"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not mention %q", err, want)
+	}
+}
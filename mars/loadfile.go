@@ -0,0 +1,188 @@
+package mars
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WriteLoadFile writes a warrior in the pMARS load file format: the
+// name and author as comments, ORG with the entry point, one fully
+// specified instruction per line with its modifier and both operands,
+// and END. PIN is written when the warrior declares one.
+func WriteLoadFile(w io.Writer, warrior *Warrior) error {
+	var b strings.Builder
+	b.WriteString(";redcode-94\n")
+	fmt.Fprintf(&b, ";name %s\n", warrior.Name)
+	if warrior.Author != "" {
+		fmt.Fprintf(&b, ";author %s\n", warrior.Author)
+	}
+	if warrior.HasPIN {
+		fmt.Fprintf(&b, "PIN %d\n", warrior.PIN)
+	}
+	fmt.Fprintf(&b, "ORG %d\n", warrior.StartOffset)
+	for _, inst := range warrior.Code {
+		b.WriteString(inst.String() + "\n")
+	}
+	b.WriteString("END\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadLoadFile reads a warrior in the load file format written by
+// WriteLoadFile and by pMARS. Unlike Redcode source, a load file has no
+// expressions or default modifiers: every instruction must give its
+// modifier and two operands as numbers. Fields may be separated by
+// spaces or tabs, and pMARS listings may label instructions and name
+// the entry point with a label.
+func ReadLoadFile(r io.Reader, color WarriorColor) (*Warrior, error) {
+	warrior := &Warrior{
+		Author: "Unknown",
+		Color:  color,
+	}
+	labels := make(map[string]int) // Instruction index of each label
+	origin, originLine := "", 0    // Argument of ORG or END
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Metadata comments
+		if name, ok := strings.CutPrefix(line, ";name "); ok {
+			warrior.Name = strings.TrimSpace(name)
+		}
+		if author, ok := strings.CutPrefix(line, ";author "); ok {
+			warrior.Author = strings.TrimSpace(author)
+		}
+
+		names, text := splitLabels(stripComment(line))
+		for _, name := range names {
+			labels[name] = len(warrior.Code)
+		}
+		if text == "" {
+			continue
+		}
+
+		keyword := firstField(text)
+		argument := strings.TrimSpace(text[len(keyword):])
+		switch strings.ToUpper(keyword) {
+		case "ORG", "END":
+			if argument != "" {
+				origin, originLine = argument, lineNum
+			}
+		case "PIN":
+			pin, err := strconv.Atoi(argument)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid PIN: %s", lineNum, argument)
+			}
+			warrior.PIN, warrior.HasPIN = pin, true
+		default:
+			inst, err := parseLoadInstruction(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNum, err)
+			}
+			warrior.Code = append(warrior.Code, inst)
+		}
+
+		// Nothing after END is loaded
+		if strings.ToUpper(keyword) == "END" {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if origin != "" {
+		if index, ok := labels[origin]; ok {
+			warrior.StartOffset = index
+		} else if n, err := strconv.Atoi(origin); err == nil {
+			warrior.StartOffset = n
+		} else {
+			return nil, fmt.Errorf("line %d: invalid start address: %s", originLine, origin)
+		}
+	}
+	if warrior.StartOffset < 0 || (warrior.StartOffset > 0 && warrior.StartOffset >= len(warrior.Code)) {
+		return nil, fmt.Errorf("start address %d is outside the warrior", warrior.StartOffset)
+	}
+	return warrior, nil
+}
+
+// parseLoadInstruction parses a fully specified instruction such as
+// "MOV.I $0, $1"
+func parseLoadInstruction(line string) (Instruction, error) {
+	opText := firstField(line)
+	operands := strings.TrimSpace(line[len(opText):])
+	opToken, modToken, hasMod := strings.Cut(opText, ".")
+	if !hasMod {
+		return Instruction{}, fmt.Errorf("%s has no modifier", opText)
+	}
+
+	op, err := parseOpCode(opToken)
+	if err != nil {
+		return Instruction{}, err
+	}
+	mod, err := parseModifier(modToken)
+	if err != nil {
+		return Instruction{}, err
+	}
+
+	aText, bText, found := strings.Cut(operands, ",")
+	if !found {
+		return Instruction{}, fmt.Errorf("%s requires two operands", opText)
+	}
+	aMode, a, err := parseLoadOperand(aText)
+	if err != nil {
+		return Instruction{}, err
+	}
+	bMode, b, err := parseLoadOperand(bText)
+	if err != nil {
+		return Instruction{}, err
+	}
+
+	return Instruction{Op: op, Modifier: mod, AMode: aMode, BMode: bMode, A: a, B: b}, nil
+}
+
+// parseLoadOperand parses an addressing mode followed by a number. pMARS
+// pads the number with spaces after the mode.
+func parseLoadOperand(s string) (AddressMode, int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DIRECT, 0, fmt.Errorf("missing operand")
+	}
+	mode, ok := parseAddressMode(s[0])
+	if !ok {
+		return DIRECT, 0, fmt.Errorf("operand %s has no addressing mode", s)
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(s[1:]))
+	if err != nil {
+		return DIRECT, 0, fmt.Errorf("invalid operand: %s", s)
+	}
+	return mode, value, nil
+}
+
+// LoadWarriorFromLoadFile loads a warrior from a load file. Warriors
+// without a ;name comment are named after the file.
+func LoadWarriorFromLoadFile(filename string, color WarriorColor) (*Warrior, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	warrior, err := ReadLoadFile(file, color)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if warrior.Name == "" {
+		warrior.Name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	return warrior, nil
+}
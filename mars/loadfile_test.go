@@ -0,0 +1,96 @@
+package mars
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkRoundTrip writes warrior as a load file, reads it back and fails
+// the test if the code, entry point or PIN changed
+func checkRoundTrip(t *testing.T, warrior *Warrior) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteLoadFile(&buf, warrior); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadLoadFile(&buf, warrior.Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.Code, warrior.Code) {
+		t.Errorf("code %v, want %v", loaded.Code, warrior.Code)
+	}
+	if loaded.StartOffset != warrior.StartOffset {
+		t.Errorf("start offset %d, want %d", loaded.StartOffset, warrior.StartOffset)
+	}
+	if loaded.PIN != warrior.PIN || loaded.HasPIN != warrior.HasPIN {
+		t.Errorf("PIN %d (%v), want %d (%v)", loaded.PIN, loaded.HasPIN, warrior.PIN, warrior.HasPIN)
+	}
+	if loaded.Name != warrior.Name {
+		t.Errorf("name %q, want %q", loaded.Name, warrior.Name)
+	}
+}
+
+func TestLoadFileRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "warriors", "*.red"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no warriors found")
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			warrior, err := LoadWarriorFromFile(file, Red, DefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkRoundTrip(t, warrior)
+		})
+	}
+
+	t.Run("PIN and ORG", func(t *testing.T) {
+		source := ";name Shared\nPIN 42\nORG start\nDAT #0, #1\nstart STP.B #1, #0\nJMP start"
+		warrior, err := LoadWarriorFromSource("Shared", source, Red, DefaultConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkRoundTrip(t, warrior)
+	})
+}
+
+func TestReadLoadFile(t *testing.T) {
+	// A listing as pMARS prints it, with a label, ORG naming that label
+	// and numbers padded after the addressing mode
+	listing := strings.Join([]string{
+		";name Dwarf",
+		"       ORG      START",
+		"       DAT.F  #     0, #     0",
+		"START  ADD.AB #     4, $    -1",
+		"       MOV.AB #     0, @    -2",
+		"       JMP.B  $    -2, $     0",
+		"       END",
+	}, "\n")
+	warrior, err := ReadLoadFile(strings.NewReader(listing), Red)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"DAT.F #0, #0", "ADD.AB #4, $-1", "MOV.AB #0, @-2", "JMP.B $-2, $0"}
+	got := make([]string, len(warrior.Code))
+	for i, inst := range warrior.Code {
+		got[i] = inst.String()
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("code %q, want %q", got, want)
+	}
+	if warrior.StartOffset != 1 {
+		t.Errorf("start offset %d, want 1", warrior.StartOffset)
+	}
+	if warrior.Name != "Dwarf" {
+		t.Errorf("name %q, want Dwarf", warrior.Name)
+	}
+}
@@ -0,0 +1,101 @@
+package mars
+
+// PSpace is a warrior's private storage (P-space), which survives
+// between the rounds of a match. Warriors that declare the same PIN
+// share every cell except cell 0, which always holds the warrior's own
+// result of the previous round.
+type PSpace struct {
+	cells  []int // cells[0] is unused; see result
+	result int
+}
+
+// Load returns the value of a P-space cell. Indices wrap around the
+// P-space size.
+func (p *PSpace) Load(index int) int {
+	index = p.index(index)
+	if index == 0 {
+		return p.result
+	}
+	return p.cells[index]
+}
+
+// Store sets the value of a P-space cell. Indices wrap around the
+// P-space size.
+func (p *PSpace) Store(index, value int) {
+	index = p.index(index)
+	if index == 0 {
+		p.result = value
+		return
+	}
+	p.cells[index] = value
+}
+
+// index maps an index onto the P-space
+func (p *PSpace) index(index int) int {
+	index %= len(p.cells)
+	if index < 0 {
+		index += len(p.cells)
+	}
+	return index
+}
+
+// PSpaces holds the P-spaces of the warriors taking part in a match.
+// Pass the same PSpaces to every round of the match so the values
+// persist from one round to the next.
+type PSpaces struct {
+	size   int
+	spaces map[*Warrior]*PSpace
+	shared map[int][]int // cells of warriors with a PIN, keyed by PIN
+}
+
+// NewPSpaces creates empty P-spaces of the given size
+func NewPSpaces(size int) *PSpaces {
+	return &PSpaces{
+		size:   size,
+		spaces: make(map[*Warrior]*PSpace),
+		shared: make(map[int][]int),
+	}
+}
+
+// For returns the P-space of a warrior, creating it on first use. A new
+// P-space holds zeros, except for cell 0 which holds -1 until the first
+// round has been recorded.
+func (ps *PSpaces) For(warrior *Warrior) *PSpace {
+	if space, ok := ps.spaces[warrior]; ok {
+		return space
+	}
+
+	var cells []int
+	if warrior.HasPIN {
+		cells = ps.shared[warrior.PIN]
+		if cells == nil {
+			cells = make([]int, ps.size)
+			ps.shared[warrior.PIN] = cells
+		}
+	} else {
+		cells = make([]int, ps.size)
+	}
+
+	space := &PSpace{cells: cells, result: -1}
+	ps.spaces[warrior] = space
+	return space
+}
+
+// RecordResult stores the outcome of a round in cell 0 of each warrior's
+// P-space: 0 for a warrior that died, otherwise the number of warriors
+// that survived the round.
+func (ps *PSpaces) RecordResult(warriors []*Warrior, alive func(*Warrior) bool) {
+	survivors := 0
+	for _, warrior := range warriors {
+		if alive(warrior) {
+			survivors++
+		}
+	}
+	for _, warrior := range warriors {
+		result := 0
+		if alive(warrior) {
+			result = survivors
+		}
+		ps.For(warrior).result = result
+	}
+}
@@ -0,0 +1,551 @@
+package mars
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RuleSet selects the execution rules applied by the VM
+type RuleSet int
+
+const (
+	StandardRules  RuleSet = iota // ICWS'94 semantics
+	OwnershipRules                // Variant: a process dies executing a cell last written by another warrior
+)
+
+// RuleSetString returns the string representation of a rule set
+func RuleSetString(rules RuleSet) string {
+	switch rules {
+	case StandardRules:
+		return "standard"
+	case OwnershipRules:
+		return "ownership"
+	default:
+		return "???"
+	}
+}
+
+// ParseRuleSet converts a rule set name to a RuleSet
+func ParseRuleSet(s string) (RuleSet, error) {
+	switch strings.ToLower(s) {
+	case "standard", "":
+		return StandardRules, nil
+	case "ownership":
+		return OwnershipRules, nil
+	default:
+		return StandardRules, fmt.Errorf("unknown rule set: %s", s)
+	}
+}
+
+// processQueue is a warrior's FIFO queue of process program counters,
+// kept in a ring buffer sized for the process limit so that running a
+// battle does not allocate
+type processQueue struct {
+	pcs   []int
+	head  int // index of the next process to execute
+	count int
+}
+
+// push adds a process to the back of the queue
+func (q *processQueue) push(pc int) {
+	q.pcs[(q.head+q.count)%len(q.pcs)] = pc
+	q.count++
+}
+
+// pop removes the process at the head of the queue
+func (q *processQueue) pop() int {
+	pc := q.pcs[q.head]
+	q.head = (q.head + 1) % len(q.pcs)
+	q.count--
+	return pc
+}
+
+// VM represents the Core War virtual machine (MARS)
+type VM struct {
+	core     *Core
+	warriors []*Warrior     // warriors in execution order
+	queues   []processQueue // process queue of each warrior in warriors
+	live     int            // number of warriors with processes
+	config   Config
+	pspaces  *PSpaces
+	trace    io.Writer // Destination of debug output, if any
+}
+
+// NewVM creates a new virtual machine running on core under the
+// process limit and rules of config
+func NewVM(core *Core, config Config) *VM {
+	return &VM{
+		core:     core,
+		warriors: make([]*Warrior, 0),
+		config:   config,
+		pspaces:  NewPSpaces(config.PSpaceSize),
+	}
+}
+
+// SetPSpaces sets the P-spaces used by LDP and STP, so that they can be
+// carried over from an earlier round
+func (vm *VM) SetPSpaces(pspaces *PSpaces) {
+	vm.pspaces = pspaces
+}
+
+// SetTrace sends debug output about process deaths to w; nil disables it
+func (vm *VM) SetTrace(w io.Writer) {
+	vm.trace = w
+}
+
+// AddProcess adds a new process to the end of a warrior's queue. The
+// process is dropped if the warrior already has MaxProcesses processes.
+func (vm *VM) AddProcess(warrior *Warrior, startAddr int) {
+	i := vm.warriorIndex(warrior)
+	if i < 0 {
+		vm.warriors = append(vm.warriors, warrior)
+		vm.queues = append(vm.queues, processQueue{pcs: make([]int, vm.config.MaxProcesses)})
+		i = len(vm.warriors) - 1
+	}
+
+	queue := &vm.queues[i]
+	if queue.count >= len(queue.pcs) {
+		return
+	}
+	if queue.count == 0 {
+		vm.live++
+	}
+	queue.push(vm.core.normalize(startAddr))
+}
+
+// warriorIndex returns the position of a warrior in execution order, or
+// -1 if it has no queue
+func (vm *VM) warriorIndex(warrior *Warrior) int {
+	for i, w := range vm.warriors {
+		if w == warrior {
+			return i
+		}
+	}
+	return -1
+}
+
+// ExecuteCycle executes one cycle of the VM. Each warrior with live
+// processes executes the instruction of the process at the head of its
+// queue; the process then rejoins the back of the queue, followed by
+// any process it created with SPL.
+func (vm *VM) ExecuteCycle() {
+	for i := range vm.queues {
+		queue := &vm.queues[i]
+		if queue.count == 0 {
+			continue
+		}
+
+		vm.executeInstruction(vm.warriors[i], queue, queue.pop())
+		if queue.count == 0 {
+			vm.live--
+		}
+	}
+}
+
+// executeInstruction executes the instruction at pc for a process of
+// warrior that has been taken off queue. The process is pushed back onto
+// the queue at its next address unless it dies, followed by the new
+// process created by SPL, if any.
+//
+// Execution follows the ICWS'94 instruction register model: the current
+// instruction is copied before anything else happens, each operand is
+// resolved to an address plus a copy of the instruction found there, and
+// opcodes compute their results from those copies rather than from the
+// live core.
+func (vm *VM) executeInstruction(warrior *Warrior, queue *processQueue, pc int) {
+	// Mark execution location
+	vm.core.Execute(pc)
+
+	// Fetch instruction into the instruction register
+	ir := vm.core.Read(pc)
+
+	// Under the ownership variant, a process dies if another warrior
+	// has overwritten this location. Ownership is tracked by warrior, as
+	// colors repeat when more than four warriors play. Standard rules
+	// only use ownership for display.
+	writer := vm.core.writers[pc]
+	if vm.config.Rules == OwnershipRules && writer != warrior && writer != nil {
+		// This location has been overwritten by another warrior
+		return
+	}
+
+	// Calculate next PC (will be overridden by jump instructions)
+	nextPC := (pc + 1) % vm.core.size
+
+	// Resolve the A operand completely before the B operand. Jumps go
+	// to the A read address; results are stored at the B write address.
+	aAddr, _, aInst := vm.resolve(pc, ir.AMode, ir.A)
+	_, bAddr, bInst := vm.resolve(pc, ir.BMode, ir.B)
+
+	// Execute based on opcode
+	switch ir.Op {
+	case DAT:
+		// Data instruction kills the process
+		if vm.trace != nil {
+			fmt.Fprintf(vm.trace, "Process died executing DAT at PC=%d (warrior: %s)\n", pc, warrior.Name)
+		}
+		return
+
+	case MOV:
+		// Move the fields selected by the modifier
+		target := vm.core.Read(bAddr)
+		vm.core.Write(bAddr, move(ir.Modifier, aInst, target), warrior)
+		pc = nextPC
+
+	case ADD, SUB, MUL, DIV, MOD:
+		// Arithmetic on the fields selected by the modifier
+		target := vm.core.Read(bAddr)
+		result, ok := vm.combine(ir.Modifier, aInst, bInst, target, arithmetic[ir.Op])
+		vm.core.Write(bAddr, result, warrior)
+		if !ok {
+			// Division by zero kills the process after the other
+			// fields have been stored
+			if vm.trace != nil {
+				fmt.Fprintf(vm.trace, "Process died dividing by zero at PC=%d (warrior: %s)\n", pc, warrior.Name)
+			}
+			return
+		}
+		pc = nextPC
+
+	case JMP:
+		// Jump instruction
+		pc = aAddr
+
+	case JMZ:
+		// Jump to A if the B operand is zero
+		if isZero(ir.Modifier, bInst) {
+			pc = aAddr
+		} else {
+			pc = nextPC
+		}
+
+	case JMN:
+		// Jump to A if the B operand is not zero
+		if !isZero(ir.Modifier, bInst) {
+			pc = aAddr
+		} else {
+			pc = nextPC
+		}
+
+	case DJN:
+		// Decrement both the B-target in core and the B operand copy,
+		// then jump to A if the copy is not zero
+		target := vm.core.Read(bAddr)
+		target, _ = vm.combine(ir.Modifier, target, target, target, decrement)
+		vm.core.Write(bAddr, target, warrior)
+		bInst, _ = vm.combine(ir.Modifier, bInst, bInst, bInst, decrement)
+
+		if !isZero(ir.Modifier, bInst) {
+			pc = aAddr
+		} else {
+			pc = nextPC
+		}
+
+	case CMP, SEQ:
+		// Compare and skip if equal
+		if equal(ir.Modifier, aInst, bInst) {
+			pc = (nextPC + 1) % vm.core.size // Skip next instruction
+		} else {
+			pc = nextPC
+		}
+
+	case SNE:
+		// Compare and skip if not equal
+		if !equal(ir.Modifier, aInst, bInst) {
+			pc = (nextPC + 1) % vm.core.size
+		} else {
+			pc = nextPC
+		}
+
+	case SLT:
+		// Skip if the A operand is less than the B operand
+		if less(ir.Modifier, aInst, bInst) {
+			pc = (nextPC + 1) % vm.core.size
+		} else {
+			pc = nextPC
+		}
+
+	case LDP:
+		// Load the P-space cell indexed by the A operand into the B-target
+		space := vm.pspaces.For(warrior)
+		target := vm.core.Read(bAddr)
+		switch ir.Modifier {
+		case ModA:
+			target.A = vm.core.normalize(space.Load(aInst.A))
+		case ModAB:
+			target.B = vm.core.normalize(space.Load(aInst.A))
+		case ModBA:
+			target.A = vm.core.normalize(space.Load(aInst.B))
+		default:
+			// .B, and .F, .X and .I which behave as .B
+			target.B = vm.core.normalize(space.Load(aInst.B))
+		}
+		vm.core.Write(bAddr, target, warrior)
+		pc = nextPC
+
+	case STP:
+		// Store the A operand in the P-space cell indexed by the B operand
+		space := vm.pspaces.For(warrior)
+		switch ir.Modifier {
+		case ModA:
+			space.Store(bInst.A, aInst.A)
+		case ModAB:
+			space.Store(bInst.B, aInst.A)
+		case ModBA:
+			space.Store(bInst.A, aInst.B)
+		default:
+			// .B, and .F, .X and .I which behave as .B
+			space.Store(bInst.B, aInst.B)
+		}
+		pc = nextPC
+
+	case SPL:
+		// Split - queue a new process at the target after this one
+		queue.push(nextPC)
+
+		// Limit processes per warrior
+		if queue.count < len(queue.pcs) {
+			queue.push(aAddr)
+		}
+		return
+
+	default:
+		// Unknown instruction acts like NOP
+		pc = nextPC
+	}
+
+	queue.push(pc)
+}
+
+// move copies the fields of src selected by mod into dst
+func move(mod Modifier, src, dst Instruction) Instruction {
+	switch mod {
+	case ModA:
+		dst.A = src.A
+	case ModB:
+		dst.B = src.B
+	case ModAB:
+		dst.B = src.A
+	case ModBA:
+		dst.A = src.B
+	case ModF:
+		dst.A = src.A
+		dst.B = src.B
+	case ModX:
+		dst.A = src.B
+		dst.B = src.A
+	case ModI:
+		dst = src
+	}
+	return dst
+}
+
+// arithmetic maps each arithmetic opcode to its field operation. An
+// operation reports false when it cannot produce a result.
+var arithmetic = map[OpCode]func(b, a int) (int, bool){
+	ADD: func(b, a int) (int, bool) { return b + a, true },
+	SUB: func(b, a int) (int, bool) { return b - a, true },
+	MUL: func(b, a int) (int, bool) { return b * a, true },
+	DIV: func(b, a int) (int, bool) {
+		if a == 0 {
+			return b, false
+		}
+		return b / a, true
+	},
+	MOD: func(b, a int) (int, bool) {
+		if a == 0 {
+			return b, false
+		}
+		return b % a, true
+	},
+}
+
+// decrement is the field operation used by DJN
+func decrement(b, _ int) (int, bool) {
+	return b - 1, true
+}
+
+// combine applies op to the field pairs of dst and src selected by mod and
+// stores each result (normalized to the core) in the corresponding field
+// of target. Fields whose operation fails are left unchanged and combine
+// reports false.
+func (vm *VM) combine(mod Modifier, src, dst, target Instruction, op func(b, a int) (int, bool)) (Instruction, bool) {
+	ok := true
+	apply := func(field *int, b, a int) {
+		result, valid := op(b, a)
+		if !valid {
+			ok = false
+			return
+		}
+		*field = vm.core.normalize(result)
+	}
+
+	switch mod {
+	case ModA:
+		apply(&target.A, dst.A, src.A)
+	case ModB:
+		apply(&target.B, dst.B, src.B)
+	case ModAB:
+		apply(&target.B, dst.B, src.A)
+	case ModBA:
+		apply(&target.A, dst.A, src.B)
+	case ModF, ModI:
+		apply(&target.A, dst.A, src.A)
+		apply(&target.B, dst.B, src.B)
+	case ModX:
+		apply(&target.A, dst.A, src.B)
+		apply(&target.B, dst.B, src.A)
+	}
+	return target, ok
+}
+
+// isZero reports whether the fields of inst selected by mod are all zero
+func isZero(mod Modifier, inst Instruction) bool {
+	switch mod {
+	case ModA, ModBA:
+		return inst.A == 0
+	case ModB, ModAB:
+		return inst.B == 0
+	default:
+		return inst.A == 0 && inst.B == 0
+	}
+}
+
+// less reports whether the fields of a selected by mod are all less
+// than the corresponding fields of b
+func less(mod Modifier, a, b Instruction) bool {
+	switch mod {
+	case ModA:
+		return a.A < b.A
+	case ModB:
+		return a.B < b.B
+	case ModAB:
+		return a.A < b.B
+	case ModBA:
+		return a.B < b.A
+	case ModX:
+		return a.A < b.B && a.B < b.A
+	default:
+		return a.A < b.A && a.B < b.B
+	}
+}
+
+// equal reports whether the fields of a and b selected by mod match
+func equal(mod Modifier, a, b Instruction) bool {
+	switch mod {
+	case ModA:
+		return a.A == b.A
+	case ModB:
+		return a.B == b.B
+	case ModAB:
+		return a.A == b.B
+	case ModBA:
+		return a.B == b.A
+	case ModF:
+		return a.A == b.A && a.B == b.B
+	case ModX:
+		return a.A == b.B && a.B == b.A
+	default:
+		return a == b
+	}
+}
+
+// resolve evaluates an operand: it applies any pre-decrement, copies the
+// instruction at the final address, then applies any post-increment. It
+// returns the final address folded by the read limit, the final address
+// folded by the write limit, and the copied instruction.
+func (vm *VM) resolve(pc int, mode AddressMode, operand int) (readAddr, writeAddr int, inst Instruction) {
+	if mode == IMMEDIATE {
+		// The value lives in the instruction itself
+		return pc, pc, vm.core.Read(pc)
+	}
+
+	readOffset := vm.fold(operand, vm.config.ReadLimit)
+	writeOffset := vm.fold(operand, vm.config.WriteLimit)
+
+	if mode != DIRECT {
+		// Increments apply to the pointer at the write offset
+		pointer := vm.core.normalize(pc + writeOffset)
+
+		switch mode {
+		case PREDECREMENT:
+			vm.core.adjustB(pointer, -1)
+		case A_PREDECREMENT:
+			vm.core.adjustA(pointer, -1)
+		}
+
+		// Indirect modes add the B field of the pointer, A-indirect modes
+		// the A field, to each offset before folding it again
+		readPointer := vm.core.Read(pc + readOffset)
+		writePointer := vm.core.Read(pointer)
+		switch mode {
+		case A_INDIRECT, A_PREDECREMENT, A_POSTINCREMENT:
+			readOffset = vm.fold(readOffset+readPointer.A, vm.config.ReadLimit)
+			writeOffset = vm.fold(writeOffset+writePointer.A, vm.config.WriteLimit)
+		default:
+			readOffset = vm.fold(readOffset+readPointer.B, vm.config.ReadLimit)
+			writeOffset = vm.fold(writeOffset+writePointer.B, vm.config.WriteLimit)
+		}
+
+		inst = vm.core.Read(pc + readOffset)
+
+		switch mode {
+		case POSTINCREMENT:
+			vm.core.adjustB(pointer, 1)
+		case A_POSTINCREMENT:
+			vm.core.adjustA(pointer, 1)
+		}
+	} else {
+		inst = vm.core.Read(pc + readOffset)
+	}
+
+	return vm.core.normalize(pc + readOffset), vm.core.normalize(pc + writeOffset), inst
+}
+
+// fold maps an offset from the executing instruction into the window of
+// the given read or write limit, as in the ICWS'94 draft: the offset is
+// reduced modulo the limit and offsets past the middle of the window
+// wrap to the matching negative offset. A limit equal to the core size
+// leaves every offset unchanged.
+func (vm *VM) fold(offset, limit int) int {
+	offset = vm.core.normalize(offset) % limit
+	if offset > limit/2 {
+		offset += vm.core.size - limit
+	}
+	return offset
+}
+
+// IsWarriorAlive checks if a warrior has any alive processes
+func (vm *VM) IsWarriorAlive(warrior *Warrior) bool {
+	return vm.ProcessCount(warrior) > 0
+}
+
+// LiveWarriors returns the number of warriors that have processes left
+func (vm *VM) LiveWarriors() int {
+	return vm.live
+}
+
+// ProcessPCs returns the program counters of a warrior's processes in
+// queue order, the next to execute first
+func (vm *VM) ProcessPCs(warrior *Warrior) []int {
+	i := vm.warriorIndex(warrior)
+	if i < 0 {
+		return nil
+	}
+	queue := &vm.queues[i]
+	pcs := make([]int, queue.count)
+	for j := range pcs {
+		pcs[j] = queue.pcs[(queue.head+j)%len(queue.pcs)]
+	}
+	return pcs
+}
+
+// ProcessCount returns the number of live processes of a warrior
+func (vm *VM) ProcessCount(warrior *Warrior) int {
+	i := vm.warriorIndex(warrior)
+	if i < 0 {
+		return 0
+	}
+	return vm.queues[i].count
+}
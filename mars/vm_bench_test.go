@@ -0,0 +1,82 @@
+package mars
+
+import "testing"
+
+// benchmarkCycles runs b.N cycles of battles between the given warriors,
+// starting a new battle with the same placement whenever one ends
+func benchmarkCycles(b *testing.B, warriors ...*Warrior) {
+	bm := NewBattleManager(DefaultConfig())
+	bm.SetSeed(1)
+	if err := bm.SetupBattle(warriors); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !bm.RunCycle() {
+			b.StopTimer()
+			if err := bm.SetupBattle(warriors); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+		}
+	}
+}
+
+// BenchmarkCycleImps measures the cost of a cycle with a single process
+// per warrior
+func BenchmarkCycleImps(b *testing.B) {
+	benchmarkCycles(b, CreateImp(), CreateImp())
+}
+
+// BenchmarkCycleDwarfs measures a cycle of two bombers writing to core
+func BenchmarkCycleDwarfs(b *testing.B) {
+	benchmarkCycles(b, CreateDwarf(), CreateStone())
+}
+
+// BenchmarkCyclePapers measures cycles of replicators, whose queues hold
+// thousands of processes and grow with SPL on every other instruction
+func BenchmarkCyclePapers(b *testing.B) {
+	benchmarkCycles(b, CreateSilkWarrior(), CreatePaperOne())
+}
+
+// BenchmarkCycleMelee measures a cycle with four warriors in the core
+func BenchmarkCycleMelee(b *testing.B) {
+	benchmarkCycles(b, CreateSilkWarrior(), CreateDwarf(), CreateVampire(), CreateQuickScan())
+}
+
+// BenchmarkBattle measures a complete round, setup included
+func BenchmarkBattle(b *testing.B) {
+	warriors := []*Warrior{CreateSilkWarrior(), CreateBomber()}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bm := NewBattleManager(DefaultConfig())
+		bm.SetSeed(int64(i))
+		if err := bm.SetupBattle(warriors); err != nil {
+			b.Fatal(err)
+		}
+		for bm.RunCycle() {
+		}
+	}
+}
+
+// BenchmarkTournament measures a short round-robin between four warriors
+// on a single worker
+func BenchmarkTournament(b *testing.B) {
+	warriors := []*Warrior{CreateDwarf(), CreateSilkWarrior(), CreateBomber(), CreateQuickScan()}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		t, err := NewTournament(warriors, 2, DefaultConfig())
+		if err != nil {
+			b.Fatal(err)
+		}
+		t.SetSeed(int64(i))
+		t.SetWorkers(1)
+		if err := t.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
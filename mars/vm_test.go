@@ -0,0 +1,493 @@
+package mars
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// modifiers lists every instruction modifier as written in Redcode
+var modifiers = []string{"A", "B", "AB", "BA", "F", "X", "I"}
+
+// testConfig returns the nano hill settings, whose small core keeps the
+// expected values short
+func testConfig() Config {
+	config, err := PresetConfig("nano")
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+// newTestVM assembles source, loads it at address 0 of an empty core and
+// starts one process at start
+func newTestVM(t *testing.T, config Config, source string, start int) (*VM, *Warrior) {
+	t.Helper()
+	warrior, err := LoadWarriorFromSource("test", source, Red, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core := NewCore(config.CoreSize)
+	for i, inst := range warrior.Code {
+		core.Load(i, inst, warrior)
+	}
+	vm := NewVM(core, config)
+	vm.AddProcess(warrior, start)
+	return vm, warrior
+}
+
+// checkCell fails the test if the cell at addr does not hold want, in
+// load file notation
+func checkCell(t *testing.T, vm *VM, addr int, want string) {
+	t.Helper()
+	if got := vm.core.cells[addr].String(); got != want {
+		t.Errorf("cell %d = %s, want %s", addr, got, want)
+	}
+}
+
+// checkPCs fails the test if the warrior's process queue does not hold
+// want, next to execute first
+func checkPCs(t *testing.T, vm *VM, warrior *Warrior, want ...int) {
+	t.Helper()
+	if got := vm.ProcessPCs(warrior); !slices.Equal(got, want) {
+		t.Errorf("processes = %v, want %v", got, want)
+	}
+}
+
+func TestMOVModifiers(t *testing.T) {
+	want := map[string]string{
+		"A":  "DAT.F #1, #4",
+		"B":  "DAT.F #3, #2",
+		"AB": "DAT.F #3, #1",
+		"BA": "DAT.F #2, #4",
+		"F":  "DAT.F #1, #2",
+		"X":  "DAT.F #2, #1",
+		"I":  "NOP.AB $1, @2",
+	}
+	for _, mod := range modifiers {
+		t.Run(mod, func(t *testing.T) {
+			source := fmt.Sprintf("MOV.%s $1, $2\nNOP.AB $1, @2\nDAT.F #3, #4", mod)
+			vm, warrior := newTestVM(t, testConfig(), source, 0)
+			vm.ExecuteCycle()
+
+			checkCell(t, vm, 2, want[mod])
+			checkPCs(t, vm, warrior, 1)
+		})
+	}
+}
+
+func TestArithmeticModifiers(t *testing.T) {
+	// The A operand holds 3, 5 and the B operand 7, 11
+	tests := []struct {
+		op   string
+		want map[string]string
+	}{
+		{"ADD", map[string]string{
+			"A": "DAT.F #10, #11", "B": "DAT.F #7, #16", "AB": "DAT.F #7, #14", "BA": "DAT.F #12, #11",
+			"F": "DAT.F #10, #16", "X": "DAT.F #12, #14", "I": "DAT.F #10, #16",
+		}},
+		{"SUB", map[string]string{
+			"A": "DAT.F #4, #11", "B": "DAT.F #7, #6", "AB": "DAT.F #7, #8", "BA": "DAT.F #2, #11",
+			"F": "DAT.F #4, #6", "X": "DAT.F #2, #8", "I": "DAT.F #4, #6",
+		}},
+		{"MUL", map[string]string{
+			"A": "DAT.F #21, #11", "B": "DAT.F #7, #55", "AB": "DAT.F #7, #33", "BA": "DAT.F #35, #11",
+			"F": "DAT.F #21, #55", "X": "DAT.F #35, #33", "I": "DAT.F #21, #55",
+		}},
+		{"DIV", map[string]string{
+			"A": "DAT.F #2, #11", "B": "DAT.F #7, #2", "AB": "DAT.F #7, #3", "BA": "DAT.F #1, #11",
+			"F": "DAT.F #2, #2", "X": "DAT.F #1, #3", "I": "DAT.F #2, #2",
+		}},
+		{"MOD", map[string]string{
+			"A": "DAT.F #1, #11", "B": "DAT.F #7, #1", "AB": "DAT.F #7, #2", "BA": "DAT.F #2, #11",
+			"F": "DAT.F #1, #1", "X": "DAT.F #2, #2", "I": "DAT.F #1, #1",
+		}},
+	}
+	for _, tt := range tests {
+		for _, mod := range modifiers {
+			t.Run(tt.op+"."+mod, func(t *testing.T) {
+				source := fmt.Sprintf("%s.%s $1, $2\nDAT.F #3, #5\nDAT.F #7, #11", tt.op, mod)
+				vm, warrior := newTestVM(t, testConfig(), source, 0)
+				vm.ExecuteCycle()
+
+				checkCell(t, vm, 2, tt.want[mod])
+				checkPCs(t, vm, warrior, 1)
+			})
+		}
+	}
+}
+
+func TestSubtractionWrapsAroundCore(t *testing.T) {
+	vm, _ := newTestVM(t, testConfig(), "SUB.AB #3, $1\nDAT.F #0, #1", 0)
+	vm.ExecuteCycle()
+
+	// 1 - 3 is stored as -2 modulo the core size of 80
+	checkCell(t, vm, 1, "DAT.F #0, #78")
+}
+
+func TestDivisionByZero(t *testing.T) {
+	// The A operand holds 0, 2 and the B operand 7, 10
+	tests := []struct {
+		instruction string
+		want        string // B operand afterwards
+	}{
+		// Fields that divide by zero are left alone and the others
+		// are still stored
+		{"DIV.F", "DAT.F #7, #5"},
+		{"MOD.F", "DAT.F #7, #0"},
+		{"DIV.X", "DAT.F #3, #10"},
+		{"MOD.X", "DAT.F #1, #10"},
+		{"DIV.A", "DAT.F #7, #10"},
+		{"MOD.AB", "DAT.F #7, #10"},
+		{"DIV.I", "DAT.F #7, #5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.instruction, func(t *testing.T) {
+			source := tt.instruction + " $1, $2\nDAT.F #0, #2\nDAT.F #7, #10"
+			vm, warrior := newTestVM(t, testConfig(), source, 0)
+			vm.ExecuteCycle()
+
+			checkCell(t, vm, 2, tt.want)
+			if vm.IsWarriorAlive(warrior) {
+				t.Errorf("process survived a division by zero")
+			}
+		})
+	}
+}
+
+func TestAFieldAddressModes(t *testing.T) {
+	// The B operand goes through the A field of cell 1 to cell 3
+	tests := []struct {
+		operand       string
+		before, after string // Cell 1
+	}{
+		{"*1", "DAT.F #2, #9", "DAT.F #2, #9"},
+		{"{1", "DAT.F #3, #9", "DAT.F #2, #9"},
+		{"}1", "DAT.F #2, #9", "DAT.F #3, #9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operand, func(t *testing.T) {
+			source := fmt.Sprintf("MOV.AB #7, %s\n%s\nDAT.F #0, #0\nDAT.F #0, #0", tt.operand, tt.before)
+			vm, _ := newTestVM(t, testConfig(), source, 0)
+			vm.ExecuteCycle()
+
+			checkCell(t, vm, 1, tt.after)
+			checkCell(t, vm, 3, "DAT.F #0, #7")
+		})
+	}
+}
+
+func TestJumpModifiers(t *testing.T) {
+	// Each instruction jumps to 2 or falls through to 1, where its B
+	// operand lies
+	tests := []struct {
+		op       string
+		operand  string
+		wantPC   map[string]int
+		wantCell map[string]string // B operand afterwards, if it changes
+	}{
+		{"JMP", "DAT.F #0, #5",
+			map[string]int{"A": 2, "B": 2, "AB": 2, "BA": 2, "F": 2, "X": 2, "I": 2}, nil},
+		{"JMZ", "DAT.F #0, #5",
+			map[string]int{"A": 2, "B": 1, "AB": 1, "BA": 2, "F": 1, "X": 1, "I": 1}, nil},
+		{"JMN", "DAT.F #0, #5",
+			map[string]int{"A": 1, "B": 2, "AB": 2, "BA": 1, "F": 2, "X": 2, "I": 2}, nil},
+		{"DJN", "DAT.F #1, #5",
+			map[string]int{"A": 1, "B": 2, "AB": 2, "BA": 1, "F": 2, "X": 2, "I": 2},
+			map[string]string{
+				"A": "DAT.F #0, #5", "B": "DAT.F #1, #4", "AB": "DAT.F #1, #4", "BA": "DAT.F #0, #5",
+				"F": "DAT.F #0, #4", "X": "DAT.F #0, #4", "I": "DAT.F #0, #4",
+			}},
+	}
+	for _, tt := range tests {
+		for _, mod := range modifiers {
+			t.Run(tt.op+"."+mod, func(t *testing.T) {
+				source := fmt.Sprintf("%s.%s $2, $1\n%s\nDAT.F #0, #0", tt.op, mod, tt.operand)
+				vm, warrior := newTestVM(t, testConfig(), source, 0)
+				vm.ExecuteCycle()
+
+				checkPCs(t, vm, warrior, tt.wantPC[mod])
+				if want, ok := tt.wantCell[mod]; ok {
+					checkCell(t, vm, 1, want)
+				} else {
+					checkCell(t, vm, 1, tt.operand)
+				}
+			})
+		}
+	}
+}
+
+func TestSkipModifiers(t *testing.T) {
+	// Each instruction skips to 2 or falls through to 1
+	tests := []struct {
+		op       string
+		a, b     string // A and B operands
+		wantSkip map[string]bool
+	}{
+		{"CMP", "DAT.F #1, #2", "DAT.F #2, #1",
+			map[string]bool{"A": false, "B": false, "AB": true, "BA": true, "F": false, "X": true, "I": false}},
+		{"SEQ", "DAT.F #1, #2", "DAT.F #2, #1",
+			map[string]bool{"A": false, "B": false, "AB": true, "BA": true, "F": false, "X": true, "I": false}},
+		{"SNE", "DAT.F #1, #2", "DAT.F #2, #1",
+			map[string]bool{"A": true, "B": true, "AB": false, "BA": false, "F": true, "X": false, "I": true}},
+		// .I also compares the opcodes, modifiers and modes
+		{"CMP", "DAT.F #1, #2", "DAT.F $1, $2",
+			map[string]bool{"A": true, "B": true, "AB": false, "BA": false, "F": true, "X": false, "I": false}},
+		{"SEQ", "DAT.F #1, #2", "DAT.F $1, $2",
+			map[string]bool{"A": true, "B": true, "AB": false, "BA": false, "F": true, "X": false, "I": false}},
+		{"SNE", "DAT.F #1, #2", "DAT.F $1, $2",
+			map[string]bool{"A": false, "B": false, "AB": true, "BA": true, "F": false, "X": true, "I": true}},
+		{"SLT", "DAT.F #1, #2", "DAT.F #3, #2",
+			map[string]bool{"A": true, "B": false, "AB": true, "BA": true, "F": false, "X": true, "I": false}},
+	}
+	for _, tt := range tests {
+		for _, mod := range modifiers {
+			t.Run(tt.op+"."+mod, func(t *testing.T) {
+				source := fmt.Sprintf("%s.%s $1, $2\n%s\n%s", tt.op, mod, tt.a, tt.b)
+				vm, warrior := newTestVM(t, testConfig(), source, 0)
+				vm.ExecuteCycle()
+
+				want := 1
+				if tt.wantSkip[mod] {
+					want = 2
+				}
+				checkPCs(t, vm, warrior, want)
+			})
+		}
+	}
+}
+
+func TestPSpaceModifiers(t *testing.T) {
+	t.Run("STP", func(t *testing.T) {
+		// The A operand holds 5, 6 and the B operand 1, 2
+		want := map[string][2]int{ // Index and value stored
+			"A": {1, 5}, "B": {2, 6}, "AB": {2, 5}, "BA": {1, 6}, "F": {2, 6}, "X": {2, 6}, "I": {2, 6},
+		}
+		for _, mod := range modifiers {
+			t.Run(mod, func(t *testing.T) {
+				source := fmt.Sprintf("STP.%s $1, $2\nDAT.F #5, #6\nDAT.F #1, #2", mod)
+				vm, warrior := newTestVM(t, testConfig(), source, 0)
+				vm.ExecuteCycle()
+
+				index, value := want[mod][0], want[mod][1]
+				if got := vm.pspaces.For(warrior).Load(index); got != value {
+					t.Errorf("P-space cell %d = %d, want %d", index, got, value)
+				}
+				checkPCs(t, vm, warrior, 1)
+			})
+		}
+	})
+
+	t.Run("LDP", func(t *testing.T) {
+		// The A operand holds 1, 2; P-space cells 1 and 2 hold 10 and 20
+		want := map[string]string{
+			"A": "DAT.F #10, #0", "B": "DAT.F #0, #20", "AB": "DAT.F #0, #10", "BA": "DAT.F #20, #0",
+			"F": "DAT.F #0, #20", "X": "DAT.F #0, #20", "I": "DAT.F #0, #20",
+		}
+		for _, mod := range modifiers {
+			t.Run(mod, func(t *testing.T) {
+				source := fmt.Sprintf("LDP.%s $1, $2\nDAT.F #1, #2\nDAT.F #0, #0", mod)
+				vm, warrior := newTestVM(t, testConfig(), source, 0)
+				space := vm.pspaces.For(warrior)
+				space.Store(1, 10)
+				space.Store(2, 20)
+				vm.ExecuteCycle()
+
+				checkCell(t, vm, 2, want[mod])
+				checkPCs(t, vm, warrior, 1)
+			})
+		}
+	})
+}
+
+func TestModifierFreeOpcodes(t *testing.T) {
+	// DAT, NOP and SPL behave the same under every modifier
+	for _, mod := range modifiers {
+		t.Run("DAT."+mod, func(t *testing.T) {
+			vm, warrior := newTestVM(t, testConfig(), "DAT."+mod+" $1, $2", 0)
+			vm.ExecuteCycle()
+			checkPCs(t, vm, warrior)
+		})
+		t.Run("NOP."+mod, func(t *testing.T) {
+			vm, warrior := newTestVM(t, testConfig(), "NOP."+mod+" $1, $2", 0)
+			vm.ExecuteCycle()
+			checkPCs(t, vm, warrior, 1)
+		})
+		t.Run("SPL."+mod, func(t *testing.T) {
+			vm, warrior := newTestVM(t, testConfig(), "SPL."+mod+" $2, $1", 0)
+			vm.ExecuteCycle()
+			checkPCs(t, vm, warrior, 1, 2)
+		})
+	}
+}
+
+func TestInstructionRegister(t *testing.T) {
+	// MOV >1, >-1 copies the cell its A pointer refers to, which is the
+	// B pointer itself. The copy is taken before the B operand's
+	// post-increment changes that cell, so the old pointer is written.
+	source := `
+		DAT.F #0, #10
+		MOV.I >1, >-1
+		DAT.F #0, #-2`
+	vm, warrior := newTestVM(t, testConfig(), source, 1)
+	vm.ExecuteCycle()
+
+	checkCell(t, vm, 10, "DAT.F #0, #10")
+	checkCell(t, vm, 0, "DAT.F #0, #11")
+	checkCell(t, vm, 2, "DAT.F #0, #79")
+	checkPCs(t, vm, warrior, 2)
+}
+
+func TestInstructionRegisterSelfModification(t *testing.T) {
+	// The MOV overwrites itself with a DAT, but completes as the MOV it
+	// was when fetched instead of killing the process
+	source := `
+		MOV.I $2, $0
+		DAT.F #0, #0
+		DAT.F #1, #1`
+	vm, warrior := newTestVM(t, testConfig(), source, 0)
+	vm.ExecuteCycle()
+
+	checkCell(t, vm, 0, "DAT.F #1, #1")
+	checkPCs(t, vm, warrior, 1)
+}
+
+func TestSPLQueueOrder(t *testing.T) {
+	config := testConfig()
+	config.MaxProcesses = 3
+	vm, warrior := newTestVM(t, config, "SPL $0\nJMP $-1", 0)
+
+	// The process rejoins the queue before the one it creates, and new
+	// processes are dropped once the queue is full
+	steps := [][]int{
+		{1, 0},
+		{0, 0},
+		{0, 1, 0},
+		{1, 0, 1},
+	}
+	for i, want := range steps {
+		vm.ExecuteCycle()
+		if got := vm.ProcessPCs(warrior); !slices.Equal(got, want) {
+			t.Fatalf("cycle %d: processes = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestQueuesTakeTurns(t *testing.T) {
+	config := testConfig()
+	paper, err := LoadWarriorFromSource("paper", "SPL $0\nJMP $-1", Red, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp, err := LoadWarriorFromSource("imp", "MOV.I $0, $1", Blue, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core := NewCore(config.CoreSize)
+	for i, inst := range paper.Code {
+		core.Load(i, inst, paper)
+	}
+	core.Load(40, imp.Code[0], imp)
+	vm := NewVM(core, config)
+	vm.AddProcess(paper, 0)
+	vm.AddProcess(imp, 40)
+
+	// However many processes the paper has, the imp moves once a cycle
+	for cycle := 1; cycle <= 10; cycle++ {
+		vm.ExecuteCycle()
+		if got := vm.ProcessPCs(imp); !slices.Equal(got, []int{40 + cycle}) {
+			t.Fatalf("cycle %d: imp at %v, want %d", cycle, got, 40+cycle)
+		}
+	}
+	if got := vm.ProcessCount(paper); got < 2 {
+		t.Errorf("paper has %d processes, want several", got)
+	}
+}
+
+func TestOwnershipRules(t *testing.T) {
+	// Two warriors of the same color: the second executes the first's
+	// code. Only the ownership rules kill it, and they must not mistake
+	// it for the owner because the colors match.
+	for _, rules := range []RuleSet{StandardRules, OwnershipRules} {
+		t.Run(RuleSetString(rules), func(t *testing.T) {
+			config := testConfig()
+			config.Rules = rules
+			vm, owner := newTestVM(t, config, "JMP $0", 0)
+			intruder := &Warrior{Name: "intruder", Color: owner.Color}
+			vm.AddProcess(intruder, 0)
+			vm.ExecuteCycle()
+
+			if !vm.IsWarriorAlive(owner) {
+				t.Errorf("owner died")
+			}
+			if alive, want := vm.IsWarriorAlive(intruder), rules == StandardRules; alive != want {
+				t.Errorf("intruder alive = %v, want %v", alive, want)
+			}
+		})
+	}
+}
+
+// newLimitVM loads the given cells, in load file notation, into an
+// empty nano core with the given read and write limits and starts one
+// process at address 20
+func newLimitVM(t *testing.T, readLimit, writeLimit int, cells map[int]string) (*VM, *Warrior) {
+	t.Helper()
+	config := testConfig()
+	config.ReadLimit = readLimit
+	config.WriteLimit = writeLimit
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	warrior := &Warrior{Name: "test", Color: Red}
+	core := NewCore(config.CoreSize)
+	for addr, source := range cells {
+		code, err := NewAssembler(config).Parse(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		core.Load(addr, code[0], warrior)
+	}
+	vm := NewVM(core, config)
+	vm.AddProcess(warrior, 20)
+	return vm, warrior
+}
+
+func TestReadWriteLimits(t *testing.T) {
+	tests := []struct {
+		name                  string
+		readLimit, writeLimit int
+		cells                 map[int]string
+		want                  map[int]string
+	}{
+		// Offsets past half the limit fold back behind the instruction
+		{"write past the limit", 80, 16,
+			map[int]string{20: "MOV.I $0, $10"},
+			map[int]string{14: "MOV.I $0, $10", 30: "DAT.F #0, #0"}},
+		{"read past the limit", 16, 80,
+			map[int]string{20: "MOV.I $10, $1", 14: "DAT.F #1, #1", 30: "DAT.F #2, #2"},
+			map[int]string{21: "DAT.F #1, #1"}},
+		{"within the limits", 16, 16,
+			map[int]string{20: "MOV.I $-7, $7", 13: "DAT.F #1, #1"},
+			map[int]string{27: "DAT.F #1, #1"}},
+		// The pointer's B field is added to the read offset and the write
+		// offset separately, and each sum is folded by its own limit: the
+		// A operand reads 21+10 folded by 16, which is 15, and the B
+		// operand writes 21+10 folded by 8, which is 23
+		{"indirect operands", 16, 8,
+			map[int]string{20: "MOV.I @1, @1", 21: "DAT.F #0, #10", 15: "DAT.F #1, #1", 31: "DAT.F #2, #2"},
+			map[int]string{23: "DAT.F #1, #1", 31: "DAT.F #2, #2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm, warrior := newLimitVM(t, tt.readLimit, tt.writeLimit, tt.cells)
+			vm.ExecuteCycle()
+			for addr, want := range tt.want {
+				checkCell(t, vm, addr, want)
+			}
+			checkPCs(t, vm, warrior, 21)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+
+	"corewar/mars"
 )
 
 // TestDwarfBehavior tests the Dwarf warrior behavior
@@ -9,22 +11,23 @@ func TestDwarfBehavior() {
 	fmt.Println("=== Testing Dwarf Behavior ===")
 
 	// Create a small core for testing
-	testCore := NewCore(100)
-	testVM := NewVM(testCore)
+	config := mars.DefaultConfig()
+	config.CoreSize = 100
+	testCore := mars.NewCore(config.CoreSize)
+	testVM := mars.NewVM(testCore, config)
 
 	// Create and load Dwarf at position 10
-	dwarf := CreateDwarf()
+	dwarf := mars.CreateDwarf()
 	startPos := 10
 
 	// Load Dwarf code
 	for i, inst := range dwarf.Code {
 		addr := (startPos + i) % 100
-		testCore.cells[addr] = inst
-		testCore.owners[addr] = dwarf.Color
+		testCore.Load(addr, inst, dwarf)
 	}
 
 	// Add process
-	testVM.AddProcess(dwarf, startPos)
+	testVM.AddProcess(dwarf, startPos+dwarf.StartOffset)
 
 	// Execute first few cycles and show what happens
 	for cycle := 0; cycle < 10; cycle++ {
@@ -32,19 +35,19 @@ func TestDwarfBehavior() {
 
 		// Show the DAT instruction that serves as pointer
 		datAddr := (startPos + 3) % 100
-		datInst := testCore.cells[datAddr]
+		datInst := testCore.Cell(datAddr)
 		fmt.Printf("  DAT at %d: A=%d, B=%d\n", datAddr, datInst.A, datInst.B)
 
 		// Execute one cycle
-		proc := testVM.processes[0]
-		fmt.Printf("  Executing at PC=%d\n", proc.pc)
+		pc := testVM.ProcessPCs(dwarf)[0]
+		fmt.Printf("  Executing at PC=%d\n", pc)
 		testVM.ExecuteCycle()
 
 		// Check if any bombs were placed
 		if cycle > 0 {
 			// The bomb should be placed at position pointed by DAT.B
 			bombAddr := (datAddr + datInst.B) % 100
-			if testCore.cells[bombAddr].Op == DAT && testCore.owners[bombAddr] == dwarf.Color {
+			if testCore.Cell(bombAddr).Op == mars.DAT && testCore.Owner(bombAddr) == dwarf.Color {
 				fmt.Printf("  BOMB placed at %d!\n", bombAddr)
 			}
 		}